@@ -0,0 +1,175 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Split comma separated paths. Empty text is no paths.
+func ParsePaths(text string) []string {
+	paths := []string{}
+	for _, path := range strings.Split(text, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func (c *Calendar) location() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+func (c *Calendar) onError(err error) {
+	if c.OnError == nil {
+		return
+	}
+	c.OnError(err)
+}
+
+// Read file again if it changed since last time. Whether it changed. Call with
+// loading lock.
+func (c *Calendar) refresh(path string) bool {
+	if c.files == nil {
+		c.files = make(map[string]*calendarFile)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		c.onError(err)
+		return false
+	}
+
+	loaded, ok := c.files[path]
+	if ok && loaded.modTime.Equal(info.ModTime()) && loaded.size == info.Size() {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		c.onError(err)
+		return false
+	}
+	defer f.Close()
+
+	events, err := Parse(f, c.location())
+	if err != nil {
+		c.onError(fmt.Errorf("%s: %w", path, err))
+		return false
+	}
+	c.files[path] = &calendarFile{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		events:  events,
+	}
+	return true
+}
+
+// Read files changed since the last load. OnChange is called if any did.
+func (c *Calendar) Load() {
+	c.loading.Lock()
+	changed := false
+	for _, path := range c.Paths {
+		if c.refresh(path) {
+			changed = true
+		}
+	}
+	if !changed {
+		c.loading.Unlock()
+		return
+	}
+
+	events := []Event{}
+	for _, path := range c.Paths {
+		if loaded, ok := c.files[path]; ok {
+			events = append(events, loaded.events...)
+		}
+	}
+	c.loading.Unlock()
+
+	c.locker.Lock()
+	c.events = events
+	c.locker.Unlock()
+
+	if c.OnChange != nil {
+		c.OnChange()
+	}
+}
+
+// Load files now and again every WatchInterval until ctx is done.
+func (c *Calendar) Start(ctx context.Context) {
+	c.Load()
+
+	go func() {
+		ticker := time.NewTicker(WatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Load()
+			}
+		}
+	}()
+}
+
+// Every event of every file as last loaded. Must not be modified.
+func (c *Calendar) Events() []Event {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.events
+}
+
+// First occurrence overlapping from to to. Occurrences starting at from count
+// if both are equal.
+func firstOverlap(events []Event, from, to time.Time) (BusyTime, bool) {
+	if !to.After(from) {
+		to = from.Add(time.Nanosecond)
+	}
+
+	var first BusyTime
+	found := false
+	for _, e := range events {
+		duration := e.End.Sub(e.Start)
+		e.each(to, func(start time.Time) bool {
+			end := start.Add(duration)
+			if !end.After(from) {
+				return true
+			}
+			if !found || start.Before(first.Start) || (start.Equal(first.Start) && end.After(first.End)) {
+				first = BusyTime{Start: start, End: end, Reason: e.Summary}
+				found = true
+			}
+			// LATER OCCURRENCES START AFTER THIS ONE.
+			return false
+		})
+	}
+	return first, found
+}
+
+// First busy time overlapping from to to. Overlapping and back to back events
+// are joined so End is when work is possible again.
+func (c *Calendar) Busy(from, to time.Time) (BusyTime, bool) {
+	events := c.Events()
+
+	busy, found := firstOverlap(events, from, to)
+	if !found {
+		return busy, false
+	}
+
+	for {
+		next, ok := firstOverlap(events, busy.End, busy.End)
+		if !ok || !next.End.After(busy.End) {
+			return busy, true
+		}
+		busy.End = next.End
+		busy.Reason += ", " + next.Reason
+	}
+}
@@ -0,0 +1,225 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+func utc(day, hour, minute int) time.Time {
+	return time.Date(2024, 12, day, hour, minute, 0, 0, time.UTC)
+}
+
+func workCalendar() *Calendar {
+	cal := &Calendar{
+		Paths:    []string{"testdata/work.ics"},
+		Location: time.UTC,
+	}
+	cal.Load()
+	return cal
+}
+
+// =====
+// TESTS
+// =====
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/work.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	events, err := Parse(f, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summaries := []string{}
+	for _, e := range events {
+		summaries = append(summaries, e.Summary)
+	}
+	expected := []string{
+		"Sprint planning",
+		"Code review with a summary long enough to be folded by the exporting tool",
+		"Standup",
+		"Standup (moved)",
+		"Sync",
+		"Offsite",
+	}
+	if strings.Join(summaries, "|") != strings.Join(expected, "|") {
+		t.Fatalf("Expected events %q, got %q", expected, summaries)
+	}
+
+	// MADRID IS UTC+1 IN WINTER.
+	review := events[1]
+	if !review.Start.Equal(utc(2, 11, 0)) || !review.End.Equal(utc(2, 11, 30)) {
+		t.Fatalf("Unexpected review time %s - %s", review.Start, review.End)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT30M":    30 * time.Minute,
+		"PT1H30M":  90 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"-PT15M":   -15 * time.Minute,
+		"P1DT2H":   26 * time.Hour,
+		"PT1H30":   -1,
+		"P1M":      -1,
+		"30M":      -1,
+		"PTXM":     -1,
+		"PT45S":    45 * time.Second,
+		"+PT1H":    time.Hour,
+		"P":        -1,
+		"PT1H1H1H": 3 * time.Hour,
+	}
+
+	for text, expected := range cases {
+		d, err := parseDuration(text)
+		if expected == -1 {
+			if err == nil {
+				t.Fatalf("Expected error parsing %q", text)
+			}
+			continue
+		}
+		if err != nil || d != expected {
+			t.Fatalf("Expected %s parsing %q, got %s %v", expected, text, d, err)
+		}
+	}
+}
+
+func TestCalendarBusy(t *testing.T) {
+	cal := workCalendar()
+
+	cases := []struct {
+		from, to time.Time
+		found    bool
+		start    time.Time
+		end      time.Time
+		reason   string
+	}{
+		// STANDUP ON MONDAY.
+		{utc(2, 8, 0), utc(2, 8, 30), false, time.Time{}, time.Time{}, ""},
+		{utc(2, 8, 50), utc(2, 9, 15), true, utc(2, 9, 0), utc(2, 9, 15), "Standup"},
+		{utc(2, 9, 10), utc(2, 9, 10), true, utc(2, 9, 0), utc(2, 9, 15), "Standup"},
+		{utc(2, 9, 15), utc(2, 9, 15), false, time.Time{}, time.Time{}, ""},
+		// PLANNING, SYNC AND REVIEW BACK TO BACK.
+		{utc(2, 9, 30), utc(2, 10, 30), true, utc(2, 10, 0), utc(2, 12, 0), "Sprint planning, Code review with a summary long enough to be folded by the exporting tool, Sync"},
+		// CANCELLED AND FREE EVENTS DON'T BLOCK.
+		{utc(2, 13, 0), utc(2, 17, 0), false, time.Time{}, time.Time{}, ""},
+		// ALL DAY ONLY IF BUSY.
+		{utc(3, 10, 0), utc(3, 11, 0), false, time.Time{}, time.Time{}, ""},
+		{utc(10, 10, 0), utc(10, 11, 0), true, utc(10, 0, 0), utc(11, 0, 0), "Offsite"},
+		// EXCLUDED AND MOVED OCCURRENCES.
+		{utc(4, 8, 0), utc(4, 10, 0), false, time.Time{}, time.Time{}, ""},
+		{utc(6, 8, 0), utc(6, 10, 0), true, utc(6, 9, 30), utc(6, 9, 45), "Standup (moved)"},
+		// WEEKLY RULE ON THE NEXT WEEK AND PAST ITS COUNT.
+		{utc(11, 8, 0), utc(11, 10, 0), true, utc(11, 9, 0), utc(11, 9, 15), "Standup"},
+		{utc(25, 8, 0), utc(25, 10, 0), false, time.Time{}, time.Time{}, ""},
+	}
+
+	for _, c := range cases {
+		busy, found := cal.Busy(c.from, c.to)
+		if found != c.found {
+			t.Fatalf("Expected busy %v from %s to %s, got %+v", c.found, c.from, c.to, busy)
+		}
+		if !found {
+			continue
+		}
+		if !busy.Start.Equal(c.start) || !busy.End.Equal(c.end) || busy.Reason != c.reason {
+			t.Fatalf("Expected %s %s - %s from %s, got %+v", c.reason, c.start, c.end, c.from, busy)
+		}
+	}
+}
+
+func TestCalendarDailyRule(t *testing.T) {
+	text := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20241202T130000Z\nDTEND:20241202T140000Z\n" +
+		"RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20241206T130000Z\nSUMMARY:Lunch\nEND:VEVENT\nEND:VCALENDAR\n"
+	events, err := Parse(strings.NewReader(text), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	starts := []time.Time{}
+	events[0].each(utc(31, 0, 0), func(start time.Time) bool {
+		starts = append(starts, start)
+		return true
+	})
+	expected := []time.Time{utc(2, 13, 0), utc(4, 13, 0), utc(6, 13, 0)}
+	if len(starts) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, starts)
+	}
+	for i := range expected {
+		if !starts[i].Equal(expected[i]) {
+			t.Fatalf("Expected %v, got %v", expected, starts)
+		}
+	}
+}
+
+// SYNCED FILES ARE READ AGAIN ON LOAD IF CHANGED. BROKEN ONES KEEP THEIR LAST
+// EVENTS. BUSY TIME NEVER READS FILES.
+func TestCalendarRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synced.ics")
+	write := func(summary string, modTime time.Time) {
+		text := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20241202T100000Z\nDTEND:20241202T110000Z\nSUMMARY:" +
+			summary + "\nEND:VEVENT\nEND:VCALENDAR\n"
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errs := []error{}
+	changes := 0
+	cal := &Calendar{
+		Paths:    []string{path},
+		Location: time.UTC,
+		OnError:  func(err error) { errs = append(errs, err) },
+		OnChange: func() { changes++ },
+	}
+
+	write("First", utc(1, 0, 0))
+	if _, found := cal.Busy(utc(2, 10, 0), utc(2, 10, 0)); found {
+		t.Fatalf("Expected nothing before load")
+	}
+	cal.Load()
+	if busy, _ := cal.Busy(utc(2, 10, 0), utc(2, 10, 0)); busy.Reason != "First" {
+		t.Fatalf("Expected First, got %+v", busy)
+	}
+
+	write("Second", utc(1, 1, 0))
+	if busy, _ := cal.Busy(utc(2, 10, 0), utc(2, 10, 0)); busy.Reason != "First" {
+		t.Fatalf("Expected First until load, got %+v", busy)
+	}
+	cal.Load()
+	if busy, _ := cal.Busy(utc(2, 10, 0), utc(2, 10, 0)); busy.Reason != "Second" {
+		t.Fatalf("Expected Second, got %+v", busy)
+	}
+
+	// UNCHANGED FILES ARE NOT A CHANGE.
+	cal.Load()
+	if changes != 2 {
+		t.Fatalf("Expected 2 changes, got %d", changes)
+	}
+
+	if err := os.WriteFile(path, []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cal.Load()
+	if busy, _ := cal.Busy(utc(2, 10, 0), utc(2, 10, 0)); busy.Reason != "Second" {
+		t.Fatalf("Expected last good events, got %+v", busy)
+	}
+	if len(errs) != 1 || changes != 2 {
+		t.Fatalf("Expected one error and no change, got %v %d", errs, changes)
+	}
+}
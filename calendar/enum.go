@@ -0,0 +1,39 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =========
+// Frequency
+// =========
+
+type Frequency int
+
+const (
+	FrequencyDaily Frequency = iota
+	FrequencyWeekly
+)
+
+func (f Frequency) String() string {
+
+	switch f {
+	case FrequencyDaily:
+		return "DAILY"
+	case FrequencyWeekly:
+		return "WEEKLY"
+	}
+
+	panic("Impossible Frequency value")
+}
+
+func ParseFrequency(s string) (Frequency, error) {
+	switch strings.ToUpper(s) {
+	case "DAILY":
+		return FrequencyDaily, nil
+	case "WEEKLY":
+		return FrequencyWeekly, nil
+	}
+	return 0, fmt.Errorf("unsupported recurrence frequency: %s", s)
+}
@@ -0,0 +1,7 @@
+package calendar
+
+import "errors"
+
+var ErrInvalidCalendar = errors.New("invalid calendar")
+var ErrInvalidDate = errors.New("invalid calendar date")
+var ErrInvalidDuration = errors.New("invalid calendar duration")
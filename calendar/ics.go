@@ -0,0 +1,457 @@
+// Minimal iCalendar (RFC 5545) reader. Recurrence supports daily and weekly
+// rules with interval, count, until and weekdays; other rules keep the first
+// occurrence only.
+
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =======
+// PARSING
+// =======
+
+// One content line: NAME;PARAM=VALUE:VALUE
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseProperty(line string) (icsProperty, bool) {
+	// COLON INSIDE QUOTED PARAMETERS IS NOT THE VALUE SEPARATOR.
+	quoted := false
+	sep := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return icsProperty{}, false
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	prop := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[sep+1:],
+	}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, true
+}
+
+// Content lines with folding undone.
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// Date or date time. Dates without zone are read on loc.
+func parseDate(prop icsProperty, loc *time.Location) (time.Time, bool, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s", ErrInvalidDate, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s", ErrInvalidDate, value)
+		}
+		return t, false, nil
+	}
+
+	// UNKNOWN ZONES, LIKE WINDOWS NAMES, FALL BACK TO loc.
+	if tzid, ok := prop.params["TZID"]; ok {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s", ErrInvalidDate, value)
+	}
+	return t, false, nil
+}
+
+// Durations like PT1H30M, P1D or P1W.
+func parseDuration(text string) (time.Duration, error) {
+	invalid := fmt.Errorf("%w: %s", ErrInvalidDuration, text)
+
+	sign := time.Duration(1)
+	rest := text
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		sign = -1
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "+"):
+		rest = rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") || len(rest) < 2 {
+		return 0, invalid
+	}
+	rest = rest[1:]
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	var total time.Duration
+	number := ""
+	inTime := false
+	for i := 0; i < len(rest); i++ {
+		ch := rest[i]
+		switch {
+		case ch == 'T':
+			inTime = true
+		case ch >= '0' && ch <= '9':
+			number += string(ch)
+		default:
+			unit, ok := units[ch]
+			// M IS MONTHS BEFORE T. NOT ALLOWED IN DURATIONS.
+			if !ok || number == "" || (ch == 'M' && !inTime) {
+				return 0, invalid
+			}
+			n, _ := strconv.Atoi(number)
+			total += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, invalid
+	}
+	return sign * total, nil
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Nil for unsupported rules so only the first occurrence is kept.
+func parseRecurrence(text string, loc *time.Location) *Recurrence {
+	rule := &Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(text, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			frequency, err := ParseFrequency(value)
+			if err != nil {
+				return nil
+			}
+			rule.Frequency = frequency
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval <= 0 {
+				return nil
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				return nil
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, _, err := parseDate(icsProperty{value: value}, loc)
+			if err != nil {
+				return nil
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				// ORDINALS LIKE 1MO ONLY MAKE SENSE ON MONTHLY RULES.
+				weekday, ok := icsWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil
+				}
+				rule.Weekdays = append(rule.Weekdays, weekday)
+			}
+		case "WKST":
+		default:
+			// BYMONTH, BYSETPOS AND THE LIKE.
+			return nil
+		}
+	}
+
+	if len(rule.Weekdays) > 0 && rule.Frequency != FrequencyWeekly {
+		return nil
+	}
+	return rule
+}
+
+// Event being read with what is needed to decide whether it blocks work.
+type icsEvent struct {
+	event        Event
+	uid          string
+	allDay       bool
+	hasEnd       bool
+	duration     time.Duration
+	transparency string
+	status       string
+	recurrenceId *time.Time
+}
+
+// Parse busy events of an iCalendar document. Dates without zone are read on
+// loc.
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := []*icsEvent{}
+	var current *icsEvent
+	// NESTED COMPONENTS LIKE ALARMS ARE SKIPPED.
+	depth := 0
+
+	for _, line := range lines {
+		prop, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && current == nil:
+			current = &icsEvent{}
+			continue
+		case prop.name == "BEGIN" && current != nil:
+			depth++
+			continue
+		case prop.name == "END" && current != nil && depth > 0:
+			depth--
+			continue
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT") && current != nil:
+			parsed = append(parsed, current)
+			current = nil
+			continue
+		}
+
+		if current == nil || depth > 0 {
+			continue
+		}
+		if err := current.set(prop, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("%w: unterminated event", ErrInvalidCalendar)
+	}
+	return busyEvents(parsed), nil
+}
+
+func (e *icsEvent) set(prop icsProperty, loc *time.Location) error {
+	switch prop.name {
+	case "SUMMARY":
+		e.event.Summary = unescape(prop.value)
+	case "UID":
+		e.uid = prop.value
+	case "DTSTART":
+		start, allDay, err := parseDate(prop, loc)
+		if err != nil {
+			return err
+		}
+		e.event.Start = start
+		e.allDay = allDay
+	case "DTEND":
+		end, _, err := parseDate(prop, loc)
+		if err != nil {
+			return err
+		}
+		e.event.End = end
+		e.hasEnd = true
+	case "DURATION":
+		duration, err := parseDuration(prop.value)
+		if err != nil {
+			return err
+		}
+		e.duration = duration
+	case "TRANSP":
+		e.transparency = strings.ToUpper(prop.value)
+	case "STATUS":
+		e.status = strings.ToUpper(prop.value)
+	case "RRULE":
+		e.event.Rule = parseRecurrence(prop.value, loc)
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exdate, _, err := parseDate(icsProperty{params: prop.params, value: value}, loc)
+			if err != nil {
+				return err
+			}
+			e.event.Exceptions = append(e.event.Exceptions, exdate)
+		}
+	case "RECURRENCE-ID":
+		id, _, err := parseDate(prop, loc)
+		if err != nil {
+			return err
+		}
+		e.recurrenceId = &id
+	}
+	return nil
+}
+
+// Keep what blocks work. Moved or cancelled occurrences of recurring events are
+// removed from them.
+func busyEvents(parsed []*icsEvent) []Event {
+	masters := map[string]*icsEvent{}
+	for _, e := range parsed {
+		if e.recurrenceId == nil && e.uid != "" {
+			masters[e.uid] = e
+		}
+	}
+	for _, e := range parsed {
+		if master, ok := masters[e.uid]; ok && e.recurrenceId != nil {
+			master.event.Exceptions = append(master.event.Exceptions, *e.recurrenceId)
+		}
+	}
+
+	events := []Event{}
+	for _, e := range parsed {
+		if e.event.Start.IsZero() || e.status == "CANCELLED" || e.transparency == "TRANSPARENT" {
+			continue
+		}
+		// ALL DAY EVENTS ARE USUALLY HOLIDAYS OR REMINDERS.
+		if e.allDay && e.transparency != "OPAQUE" {
+			continue
+		}
+
+		switch {
+		case e.hasEnd:
+		case e.duration > 0:
+			e.event.End = e.event.Start.Add(e.duration)
+		case e.allDay:
+			e.event.End = e.event.Start.AddDate(0, 0, 1)
+		}
+		if !e.event.End.After(e.event.Start) {
+			continue
+		}
+		events = append(events, e.event)
+	}
+	return events
+}
+
+func unescape(text string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(text)
+}
+
+// ==========
+// RECURRENCE
+// ==========
+
+// Longest expansion of a recurring event, past ten years of daily meetings.
+const maxOccurrences = 5000
+
+// Call f with the start of every occurrence before to, in order, until it
+// returns false.
+func (e Event) each(to time.Time, f func(start time.Time) bool) {
+	if e.Rule == nil {
+		if e.Start.Before(to) {
+			f(e.Start)
+		}
+		return
+	}
+
+	n := 0
+	emit := func(start time.Time) bool {
+		n++
+		if e.Rule.Count > 0 && n > e.Rule.Count {
+			return false
+		}
+		if !e.Rule.Until.IsZero() && start.After(e.Rule.Until) {
+			return false
+		}
+		if !start.Before(to) || n > maxOccurrences {
+			return false
+		}
+		if e.excluded(start) {
+			return true
+		}
+		return f(start)
+	}
+
+	y, m, d := e.Start.Date()
+	h, mi, s := e.Start.Clock()
+	loc := e.Start.Location()
+	at := func(days int) time.Time {
+		return time.Date(y, m, d+days, h, mi, s, e.Start.Nanosecond(), loc)
+	}
+
+	if e.Rule.Frequency == FrequencyDaily {
+		for k := 0; ; k += e.Rule.Interval {
+			if !emit(at(k)) {
+				return
+			}
+		}
+	}
+
+	weekdays := e.Rule.Weekdays
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{e.Start.Weekday()}
+	}
+	// WEEKS START ON MONDAY.
+	offsets := make([]bool, 7)
+	for _, wd := range weekdays {
+		offsets[(int(wd)+6)%7] = true
+	}
+	weekStart := -((int(e.Start.Weekday()) + 6) % 7)
+
+	for week := 0; ; week += e.Rule.Interval {
+		for offset, ok := range offsets {
+			days := weekStart + 7*week + offset
+			if !ok || days < 0 {
+				continue
+			}
+			if !emit(at(days)) {
+				return
+			}
+		}
+	}
+}
+
+func (e Event) excluded(start time.Time) bool {
+	for _, exception := range e.Exceptions {
+		if exception.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
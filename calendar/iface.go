@@ -0,0 +1,68 @@
+// Busy time read from local iCalendar files, exported or synced by other
+// tools. Only what blocks work is kept: timed events that are not cancelled or
+// marked free. All day events count only if marked busy.
+
+package calendar
+
+import (
+	"sync"
+	"time"
+)
+
+// How often Start looks for changed files.
+const WatchInterval = 30 * time.Second
+
+// Calendar files read again whenever they change. Busy time is answered from
+// the events last loaded so it never waits for files.
+type Calendar struct {
+	Paths []string
+	// Location of dates without time zone. Local if nil.
+	Location *time.Location
+	// Files that cannot be read keep their last events.
+	OnError func(err error)
+	// Called after a load changed the events. Ignored if nil.
+	OnChange func()
+
+	// Only used while loading.
+	files   map[string]*calendarFile
+	loading sync.Mutex
+
+	// Replaced as a whole on load, never modified.
+	events []Event
+	locker sync.Mutex
+}
+
+type calendarFile struct {
+	modTime time.Time
+	size    int64
+	events  []Event
+}
+
+// Time work is not possible, joining back to back events.
+type BusyTime struct {
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+// Busy event. Recurring events repeat every Interval of Frequency.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+	Rule    *Recurrence
+	// Occurrences removed from the recurrence.
+	Exceptions []time.Time
+}
+
+// Supported subset of recurrence rules.
+type Recurrence struct {
+	Frequency Frequency
+	Interval  int
+	// Number of occurrences. No limit if zero.
+	Count int
+	// Last occurrence start. No limit if zero.
+	Until time.Time
+	// Weekly rules only. Same day as start if empty.
+	Weekdays []time.Weekday
+}
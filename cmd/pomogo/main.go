@@ -16,7 +16,7 @@ var Commit string
 //go:embed version.txt
 var Version string
 
-var helpMessage = "No command. Use `server`, `client` or `version`."
+var helpMessage = "No command. Use `server`, `client`, `report` or `version`."
 
 func onErr(err error) {
 	if err == nil {
@@ -42,6 +42,10 @@ func main() {
 	case "client":
 		clCfg, err := config.ClientCmdArgParse(subArgs...)
 		onErr(err)
+		if clCfg.IsWatch() {
+			onErr(clCfg.Watch(os.Stdout))
+			return
+		}
 		st, err := clCfg.Run()
 		onErr(err)
 		// TODO: IMPROVE ON JSON PRINT STYLE...
@@ -49,6 +53,10 @@ func main() {
 		r, err := json.MarshalIndent(st, "", "\t")
 		onErr(err)
 		fmt.Println(string(r))
+	case "report":
+		repCfg, err := config.ReportCmdArgParse(subArgs...)
+		onErr(err)
+		onErr(repCfg.Run(os.Stdout))
 	case "version":
 		fmt.Printf(
 			"Version: %s\nCommit: %s\n",
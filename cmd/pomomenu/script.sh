@@ -19,7 +19,12 @@ getProp(){
 case $(getProp State) in
     "Work") 
         $()
-        notify-send "👷 Working" "$(getProp TimeLeft) left"
+        # FLOWTIME WORK COUNTS UP.
+        if [ "$(getProp TimeLeft)" = "null" ]; then
+            notify-send "👷 Working" "$(getProp Elapsed) worked. Play for a break"
+        else
+            notify-send "👷 Working" "$(getProp TimeLeft) left"
+        fi
         ;;
     "ShortBreak") 
         notify-send "⏲  Short break" "Back in $(getProp TimeLeft)"
@@ -33,6 +38,9 @@ case $(getProp State) in
     "Stopped") 
         notify-send "⏹ Stopped" "Play to start a new session"
         ;;
+    "Overtime") 
+        notify-send "⏰ Overtime" "$(getProp Overtime) over. Play to continue"
+        ;;
     *)
         >&2 echo "Unrecognized $(getProp State) status..."
         exit 1
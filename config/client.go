@@ -1,18 +1,57 @@
 package config
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/FernandoAFS/pomogo/controller"
 	"github.com/FernandoAFS/pomogo/server"
+	"io"
 	"os"
 	"strings"
+	"time"
 )
 
 type ClientConfig struct {
 	connectProto   string
 	connectAddress string
 	action         string
+	label          controller.SessionLabel
+	delta          time.Duration
+	interrupt      server.InterruptRequest
+	every          time.Duration
+}
+
+// Repeatable string flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// Parse label flags given after play or label actions.
+func labelArgParse(action string, args ...string) (controller.SessionLabel, error) {
+	fs := flag.NewFlagSet(action, flag.ExitOnError)
+
+	task := fs.String("task", "", "Name of the task being worked on.")
+	project := fs.String("project", "", "Project the task belongs to.")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "Free form tag. May be repeated.")
+
+	if err := fs.Parse(args); err != nil {
+		return controller.SessionLabel{}, err
+	}
+
+	return controller.SessionLabel{
+		Task:    *task,
+		Project: *project,
+		Tags:    tags,
+	}, nil
 }
 
 // Generate object from flags.
@@ -43,10 +82,49 @@ func ClientCmdArgParse(args ...string) (*ClientConfig, error) {
 
 	action := fs.Arg(0)
 
+	var label controller.SessionLabel
+	var delta time.Duration
+	var interrupt server.InterruptRequest
+	every := time.Second
+	switch strings.ToLower(action) {
+	case "play", "label":
+		label, err = labelArgParse(action, fs.Args()[1:]...)
+		if err != nil {
+			return nil, err
+		}
+	case "extend":
+		// NEGATIVE DURATIONS SHORTEN THE STATE.
+		delta, err = time.ParseDuration(fs.Arg(1))
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument: %s", fs.Arg(1))
+		}
+	case "interrupt":
+		// interrupt <internal|external> [note...]
+		interrupt.Kind, err = controller.ParseInterruptionKind(fs.Arg(1))
+		if err != nil {
+			return nil, err
+		}
+		if fs.NArg() > 2 {
+			interrupt.Note = strings.Join(fs.Args()[2:], " ")
+		}
+	case "watch":
+		// watch [interval]
+		if fs.NArg() > 1 {
+			every, err = time.ParseDuration(fs.Arg(1))
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument: %s", fs.Arg(1))
+			}
+		}
+	}
+
 	cc := &ClientConfig{
 		connectProto:   *connectProto,
 		connectAddress: *connectAddress,
 		action:         action,
+		label:          label,
+		delta:          delta,
+		interrupt:      interrupt,
+		every:          every,
 	}
 
 	return cc, nil
@@ -66,12 +144,37 @@ func (cc *ClientConfig) Run() (*controller.PomoControllerStatus, error) {
 	case "pause":
 		return cl.Pause()
 	case "play":
-		return cl.Play()
+		return cl.Play(cc.label)
 	case "skip":
 		return cl.Skip()
 	case "stop":
 		return cl.Stop()
+	case "label":
+		return cl.Label(cc.label)
+	case "extend":
+		return cl.Extend(cc.delta)
+	case "interrupt":
+		return cl.Interrupt(cc.interrupt)
 	}
 
 	return nil, fmt.Errorf("invalid argument: %s", cc.action)
 }
+
+// Watch streams ticks instead of returning a single status.
+func (cc *ClientConfig) IsWatch() bool {
+	return strings.ToLower(cc.action) == "watch"
+}
+
+// Write a json line per tick until the connection is lost.
+func (cc *ClientConfig) Watch(w io.Writer) error {
+	cl, err := server.PomogoRpcClientFactory(cc.connectProto, cc.connectAddress)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	return cl.Watch(cc.every, func(tick controller.PomoControllerTick) error {
+		// MARSHALERS HAVE POINTER RECEIVERS.
+		return enc.Encode(&tick)
+	})
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/FernandoAFS/pomogo/history"
+)
+
+type ReportConfig struct {
+	historyFile  string
+	groupBy      string
+	format       string
+	since        string
+	location     *time.Location
+	rolloverHour int
+}
+
+// Generate object from flags.
+func ReportCmdArgParse(args ...string) (*ReportConfig, error) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+
+	defHistoryFile, err := history.DefaultJournalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	historyFile := fs.String(
+		"history_file",
+		defHistoryFile,
+		"History journal written by the server.",
+	)
+
+	groupBy := fs.String(
+		"by",
+		"day",
+		"Group intervals by day, week, label or project.",
+	)
+
+	format := fs.String(
+		"format",
+		"text",
+		"Output format. Use text or json.",
+	)
+
+	sinceText := fs.String(
+		"since",
+		"",
+		"Only include intervals done on or after this date (YYYY-MM-DD).",
+	)
+
+	timezone := fs.String(
+		"timezone",
+		"Local",
+		"Time zone of calendar days, e.g. Europe/Madrid.",
+	)
+
+	rolloverHour := fs.Int(
+		"day_rollover_hour",
+		0,
+		"Hour a new day starts at. Sessions before it count for the day before.",
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if *rolloverHour < 0 || *rolloverHour > 23 {
+		return nil, fmt.Errorf("invalid argument: %d", *rolloverHour)
+	}
+
+	if _, err := history.ReportKeyByName(*groupBy, location, *rolloverHour); err != nil {
+		return nil, err
+	}
+
+	if *format != "text" && *format != "json" {
+		return nil, fmt.Errorf("invalid argument: %s", *format)
+	}
+
+	if *sinceText != "" {
+		if _, err := time.Parse(time.DateOnly, *sinceText); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ReportConfig{
+		historyFile:  *historyFile,
+		groupBy:      *groupBy,
+		format:       *format,
+		since:        *sinceText,
+		location:     location,
+		rolloverHour: *rolloverHour,
+	}, nil
+}
+
+// Read history and write the report
+func (rc *ReportConfig) Run(w io.Writer) error {
+	journal := history.FileJournal{Path: rc.historyFile}
+	intervals, err := journal.Read()
+	if err != nil {
+		return err
+	}
+
+	// SAME DAYS AS THE DAY GROUPING. DATES SORT AS TEXT.
+	if rc.since != "" {
+		day := history.ReportByDay(rc.location, rc.rolloverHour)
+		filtered := []history.Interval{}
+		for _, interval := range intervals {
+			if day(interval) >= rc.since {
+				filtered = append(filtered, interval)
+			}
+		}
+		intervals = filtered
+	}
+
+	key, err := history.ReportKeyByName(rc.groupBy, rc.location, rc.rolloverHour)
+	if err != nil {
+		return err
+	}
+	rows := history.BuildReport(intervals, key)
+
+	if rc.format == "json" {
+		return history.WriteReportJson(w, rows)
+	}
+	return history.WriteReportText(w, rows)
+}
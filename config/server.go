@@ -1,8 +1,11 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/rpc"
@@ -11,7 +14,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/FernandoAFS/pomogo/calendar"
 	"github.com/FernandoAFS/pomogo/controller"
+	"github.com/FernandoAFS/pomogo/history"
+	"github.com/FernandoAFS/pomogo/schedule"
 	"github.com/FernandoAFS/pomogo/server"
 	"github.com/FernandoAFS/pomogo/session"
 	"github.com/FernandoAFS/pomogo/timer"
@@ -24,7 +30,31 @@ type ServerConfig struct {
 	workDuration       time.Duration
 	shortBreakDuration time.Duration
 	longBreakDuration  time.Duration
-	command            string
+	hooks              []controller.Hook
+	// Shared by every hook so limits apply to all of them.
+	hookRunner  *controller.HookRunner
+	sequence    []session.SessionSequenceStep
+	historyFile string
+	stateFile   string
+	// Nil if there is no state file.
+	stateWriter   *controller.SnapshotFileWriter
+	restorePolicy controller.PomoControllerRestorePolicy
+	jumpPolicy    controller.PomoControllerJumpPolicy
+	advancePolicy controller.PomoControllerAdvancePolicy
+	voidThreshold int
+	flowtime      bool
+	flowtimeRatio float64
+	flowtimeTable []session.FlowtimeBreakStep
+	dailyGoal     int
+	location      *time.Location
+	rolloverHour  int
+	warnings      controller.PomoControllerWarnings
+	// Nil if there are no rules.
+	schedule *schedule.Schedule
+	// Nil if there are no calendar files.
+	calendar *calendar.Calendar
+	// Shared by timers, controller and server. Real clock if nil.
+	clock timer.Clock
 }
 
 func ServerCmdArgParse(args ...string) (*ServerConfig, error) {
@@ -77,6 +107,143 @@ func ServerCmdArgParse(args ...string) (*ServerConfig, error) {
 		"Command to be runned on every controller event (but error)",
 	)
 
+	var onHooks stringsFlag
+	fs.Var(
+		&onHooks,
+		"on",
+		"Command run on some events, e.g. play=./start.sh or endofstate,stop=./notify.sh. May be repeated.",
+	)
+
+	hooksDir := fs.String(
+		"hooks_dir",
+		"",
+		"Directory of executables run on every event, in lexical order.",
+	)
+
+	hookTimeout := fs.Duration(
+		"hook_timeout",
+		30*time.Second,
+		"Hooks running longer are killed. Zero disables it.",
+	)
+
+	hookConcurrency := fs.Int(
+		"hook_concurrency",
+		4,
+		"Hook processes running at once.",
+	)
+
+	sequenceText := fs.String(
+		"sequence",
+		"",
+		"Custom cycle of states, e.g. work,short,work,long or work:52m,short:17m. Overrides work_sessions.",
+	)
+
+	defHistoryFile, err := history.DefaultJournalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	historyFile := fs.String(
+		"history_file",
+		defHistoryFile,
+		"Append only journal of finished intervals. Empty to disable.",
+	)
+
+	defStateFile, err := controller.DefaultSnapshotPath()
+	if err != nil {
+		return nil, err
+	}
+
+	stateFile := fs.String(
+		"state_file",
+		defStateFile,
+		"File to save session state on every change and restore it on startup. Empty to disable.",
+	)
+
+	restorePolicyText := fs.String(
+		"restore_policy",
+		"catchup",
+		"What to do with a running session on restore. Use catchup to move through the states that elapsed while down or pause to resume paused.",
+	)
+
+	jumpPolicyText := fs.String(
+		"jump_policy",
+		"fire",
+		"What to do when the clock jumps, like after a system suspend. Use fire to end an expired state right away, catchup to move through the states that elapsed or pause to pause when the jump started.",
+	)
+
+	manualAfterWork := fs.Bool(
+		"manual_after_work",
+		false,
+		"Wait for play to start the break once work time is over. Overtime is counted meanwhile.",
+	)
+
+	manualAfterBreak := fs.Bool(
+		"manual_after_break",
+		false,
+		"Wait for play to start working once break time is over. Overtime is counted meanwhile.",
+	)
+
+	voidThreshold := fs.Int(
+		"void_interruptions",
+		0,
+		"Start the work interval over once it has more interruptions than this. 0 to disable.",
+	)
+
+	flowtime := fs.Bool(
+		"flowtime",
+		false,
+		"Open ended work intervals that count up until play or skip. work_duration is the minimum, 0 unless set.",
+	)
+
+	flowtimeRatio := fs.Float64(
+		"flowtime_ratio",
+		0.2,
+		"Flowtime break duration as a fraction of the time worked.",
+	)
+
+	flowtimeTableText := fs.String(
+		"flowtime_table",
+		"",
+		"Flowtime breaks by time worked, e.g. 25m=5m,50m=8m,90m=10m. Overrides flowtime_ratio.",
+	)
+
+	dailyGoal := fs.Int(
+		"daily_goal",
+		0,
+		"Number of work sessions to complete every day. 0 to disable.",
+	)
+
+	timezone := fs.String(
+		"timezone",
+		"Local",
+		"Time zone of calendar days, e.g. Europe/Madrid.",
+	)
+
+	rolloverHour := fs.Int(
+		"day_rollover_hour",
+		0,
+		"Hour a new day starts at. Sessions before it count for the day before.",
+	)
+
+	warningsText := fs.String(
+		"warnings",
+		"",
+		"Warn some time before the end of states, e.g. work:2m,short:1m,long:1m.",
+	)
+
+	scheduleText := fs.String(
+		"schedule",
+		"",
+		"Actions at given times as cron rules separated by ;, e.g. '0 9 * * 1-5 play; 0 13 * * 1-5 long 1h; 0 18 * * 1-5 stop'. Actions are play, stop, pause and long.",
+	)
+
+	calendarText := fs.String(
+		"calendar",
+		"",
+		"iCalendar files separated by commas. Work ends before busy events, play is refused during them and work starting on its own pauses.",
+	)
+
 	if err := fs.Parse(args); err != nil {
 		return nil, err
 	}
@@ -89,6 +256,141 @@ func ServerCmdArgParse(args ...string) (*ServerConfig, error) {
 	}
 	// TODO: CROSS CHECK PROTOCOL AND ADDRESS.
 
+	restorePolicy, err := controller.ParseRestorePolicy(*restorePolicyText)
+	if err != nil {
+		return nil, err
+	}
+
+	jumpPolicy, err := controller.ParseJumpPolicy(*jumpPolicyText)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if *rolloverHour < 0 || *rolloverHour > 23 {
+		return nil, fmt.Errorf("invalid argument: %d", *rolloverHour)
+	}
+
+	if *hookTimeout < 0 {
+		return nil, fmt.Errorf("invalid argument: %s", *hookTimeout)
+	}
+
+	if *hookConcurrency < 1 {
+		return nil, fmt.Errorf("invalid argument: %d", *hookConcurrency)
+	}
+
+	hooks := []controller.Hook{}
+	if *command != "" {
+		hooks = append(hooks, controller.Hook{Command: *command})
+	}
+	for _, text := range onHooks {
+		hook, err := controller.ParseHook(text)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	if *hooksDir != "" {
+		info, err := os.Stat(*hooksDir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("invalid argument: %s is not a directory", *hooksDir)
+		}
+		hooks = append(hooks, controller.Hook{Dir: *hooksDir})
+	}
+
+	warnings, err := controller.ParseWarnings(*warningsText)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := schedule.ParseRules(*scheduleText)
+	if err != nil {
+		return nil, err
+	}
+
+	var flowtimeTable []session.FlowtimeBreakStep
+	if *flowtimeTableText != "" {
+		flowtimeTable, err = session.ParseFlowtimeTable(*flowtimeTableText)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if *flowtimeRatio < 0 {
+		return nil, fmt.Errorf("invalid argument: %f", *flowtimeRatio)
+	}
+
+	// FLOWTIME WORK HAS NO MINIMUM UNLESS GIVEN.
+	if *flowtime {
+		workSet := false
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "work_duration" {
+				workSet = true
+			}
+		})
+		if !workSet {
+			*workDuration = 0
+		}
+	}
+
+	var sequence []session.SessionSequenceStep
+	if *sequenceText != "" {
+		sequence, err = session.ParseSequence(*sequenceText)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clock := timer.RealClock{}
+
+	var sched *schedule.Schedule
+	if len(rules) > 0 {
+		sched = &schedule.Schedule{
+			Rules:    rules,
+			Location: location,
+			Clock:    clock,
+			OnError: func(err error) {
+				slog.Error("Cannot run scheduled action", "err", err)
+			},
+		}
+	}
+
+	var cal *calendar.Calendar
+	if paths := calendar.ParsePaths(*calendarText); len(paths) > 0 {
+		cal = &calendar.Calendar{
+			Paths:    paths,
+			Location: location,
+			OnError: func(err error) {
+				slog.Error("Cannot read calendar", "err", err)
+			},
+		}
+	}
+
+	var stateWriter *controller.SnapshotFileWriter
+	if *stateFile != "" {
+		stateWriter = &controller.SnapshotFileWriter{
+			Path: *stateFile,
+			OnError: func(err error) {
+				slog.Error("Cannot save state", "err", err)
+			},
+		}
+	}
+
+	hookRunner := &controller.HookRunner{
+		Timeout:       *hookTimeout,
+		MaxConcurrent: *hookConcurrency,
+		OnError: func(err error) {
+			slog.Error("Hook failed", "err", err)
+		},
+	}
+
 	return &ServerConfig{
 		nSessions:          *nSessions,
 		listenProto:        *listenProto,
@@ -96,18 +398,46 @@ func ServerCmdArgParse(args ...string) (*ServerConfig, error) {
 		workDuration:       *workDuration,
 		shortBreakDuration: *shortBreakDuration,
 		longBreakDuration:  *longBreakDuration,
-		command:            *command,
+		hooks:              hooks,
+		hookRunner:         hookRunner,
+		sequence:           sequence,
+		historyFile:        *historyFile,
+		stateFile:          *stateFile,
+		stateWriter:        stateWriter,
+		restorePolicy:      restorePolicy,
+		jumpPolicy:         jumpPolicy,
+		advancePolicy: controller.PomoControllerAdvancePolicy{
+			ManualAfterWork:  *manualAfterWork,
+			ManualAfterBreak: *manualAfterBreak,
+		},
+		voidThreshold: *voidThreshold,
+		flowtime:      *flowtime,
+		flowtimeRatio: *flowtimeRatio,
+		flowtimeTable: flowtimeTable,
+		dailyGoal:     *dailyGoal,
+		location:      location,
+		rolloverHour:  *rolloverHour,
+		warnings:      warnings,
+		schedule:      sched,
+		calendar:      cal,
+		clock:         clock,
 	}, nil
 }
 
 func (sc *ServerConfig) sessionFactory() session.PomoSessionIface {
+	if len(sc.sequence) > 0 {
+		// SEQUENCE IS VALIDATED ON PARSE.
+		s, _ := session.NewSequenceSession(sc.sequence)
+		return s
+	}
 	return &session.PomoSession{
 		WorkSessionsBreak: sc.nSessions,
 	}
 }
 
+// Wall clock timer so states end on time after a system suspend.
 func (sc *ServerConfig) timerFactory() timer.PomoTimerIface {
-	return new(timer.PomoTimer)
+	return &timer.WallTimer{Clock: sc.clock}
 }
 
 func (sc *ServerConfig) durationFactory() session.SessionStateDurationFactory {
@@ -118,16 +448,110 @@ func (sc *ServerConfig) durationFactory() session.SessionStateDurationFactory {
 	)
 }
 
-func (sc *ServerConfig) controllerFactory() (controller.PomoControllerIface, error) {
+// Calendar busy time as controller busy time.
+type calendarBusy struct {
+	calendar *calendar.Calendar
+}
+
+func (c calendarBusy) Busy(from, to time.Time) (controller.PomoControllerBusy, bool) {
+	busy, ok := c.calendar.Busy(from, to)
+	return controller.PomoControllerBusy{
+		Start:  busy.Start,
+		End:    busy.End,
+		Reason: busy.Reason,
+	}, ok
+}
+
+func (sc *ServerConfig) historyRecorder() *history.Recorder {
+	return &history.Recorder{
+		Journal: &history.FileJournal{Path: sc.historyFile},
+		OnError: func(err error) {
+			slog.Error("Cannot write history", "err", err)
+		},
+	}
+}
+
+func (sc *ServerConfig) controllerFactory(ctx context.Context) (controller.PomoControllerIface, error) {
+
+	// SESSION AND DURATION FACTORY MUST SHARE THE SAME INSTANCE FOR PER-STEP
+	// DURATIONS.
+	sess := sc.sessionFactory()
+	durationF := sc.durationFactory()
+	var flow *session.FlowtimeDuration
+	if sc.flowtime {
+		flow = &session.FlowtimeDuration{
+			Base:  durationF,
+			Ratio: sc.flowtimeRatio,
+			Table: sc.flowtimeTable,
+		}
+		durationF = flow.GetDurationFactory()
+	}
+	if seq, ok := sess.(*session.PomoSequenceSession); ok {
+		durationF = seq.GetDurationFactory(durationF)
+	}
 
 	options := []controller.PomoControllerOption{
-		controller.PomoControllerSessionOpt(sc.sessionFactory),
+		controller.PomoControllerSessionOpt(func() session.PomoSessionIface {
+			return sess
+		}),
 		controller.PomoControllerTimerOpt(sc.timerFactory),
-		controller.PomoControllerDurationF(sc.durationFactory),
+		controller.PomoControllerOptionClock(sc.clock),
+		controller.PomoControllerDurationF(func() session.SessionStateDurationFactory {
+			return durationF
+		}),
+		controller.PomoControllerOptionAdvancePolicy(sc.advancePolicy),
+		controller.PomoControllerOptionOpenWork(sc.flowtime),
+		controller.PomoControllerOptionVoidThreshold(sc.voidThreshold),
+		controller.PomoControllerOptionJumpPolicy(sc.jumpPolicy),
+		controller.PomoControllerOptionWarnings(sc.warnings),
+		controller.PomoControllerWarningTimerOpt(sc.timerFactory),
+		controller.PomoControllerOptionContext(ctx),
+	}
+
+	for _, hook := range sc.hooks {
+		options = append(options, controller.PomoControllerOptionHook(
+			ctx,
+			sc.hookRunner,
+			hook,
+		))
+	}
+
+	if sc.schedule != nil {
+		options = append(options, controller.PomoControllerOptionSchedule(sc.schedule))
+	}
+
+	if sc.calendar != nil {
+		options = append(options, controller.PomoControllerOptionCalendar(
+			calendarBusy{sc.calendar},
+		))
+	}
+
+	// BREAK DEPENDS ON THE TIME WORKED. INFORMED BEFORE THE BREAK STARTS.
+	if flow != nil {
+		options = append(options, controller.PomoControllerOptionWorked(flow))
+	}
+
+	// WRITES TO DISK. KEPT OFF THE CONTROLLER LOCK.
+	if sc.historyFile != "" {
+		options = append(options, controller.PomoControllerOptionSubscriber(
+			sc.historyRecorder().Listener().Sink(),
+		))
+	}
+
+	if sc.dailyGoal > 0 {
+		options = append(options, controller.PomoControllerOptionDailyGoal(
+			&controller.DailyGoal{
+				Target:       sc.dailyGoal,
+				Location:     sc.location,
+				RolloverHour: sc.rolloverHour,
+			},
+		))
 	}
 
-	if sc.command != "" {
-		options = append(options, controller.PomoControllerHook(sc.command))
+	if sc.stateWriter != nil {
+		options = append(options, controller.PomoControllerOptionSnapshotSink(
+			sc.stateWriter.Save,
+		))
 	}
 
 	return controller.ControllerFactory(
@@ -135,47 +559,147 @@ func (sc *ServerConfig) controllerFactory() (controller.PomoControllerIface, err
 	)
 }
 
-func (sc *ServerConfig) controllerFactoryPanic() controller.PomoControllerIface {
-	ctrl, err := sc.controllerFactory()
+func (sc *ServerConfig) controllerFactoryPanic(ctx context.Context) controller.PomoControllerIface {
+	ctrl, err := sc.controllerFactory(ctx)
 	if err != nil {
 		panic(err)
 	}
 	return ctrl
 }
 
-func (sc *ServerConfig) containerFactory() *controller.SingleControllerContainer {
-	return &controller.SingleControllerContainer{
-		ControllerFactory: sc.controllerFactoryPanic,
+func (sc *ServerConfig) containerFactory(ctx context.Context) *controller.SingleControllerContainer {
+	container := &controller.SingleControllerContainer{
+		ControllerFactory: func() controller.PomoControllerIface {
+			return sc.controllerFactoryPanic(ctx)
+		},
+	}
+	// RESTORED WORK CHECKS BUSY TIME TOO.
+	sc.startCalendar(ctx, container)
+	if sc.stateFile != "" {
+		sc.restoreController(container)
+	}
+	return container
+}
+
+// Create controller from saved state if any. Errors are logged and the server
+// starts fresh.
+func (sc *ServerConfig) restoreController(container *controller.SingleControllerContainer) {
+	snapshot, err := controller.LoadSnapshotFile(sc.stateFile)
+	if err != nil {
+		slog.Error("Cannot load state", "err", err)
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+
+	ctrl, ok := container.CreateController().(*controller.PomoController)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.Restore(timer.ClockOrReal(sc.clock).Now(), *snapshot, sc.restorePolicy); err != nil {
+		slog.Warn("Cannot restore state", "err", err)
+		return
 	}
+	slog.Info("State restored", "status", ctrl.Status())
+}
+
+func (sc *ServerConfig) serverFactory(ctx context.Context) (*server.SingleSessionServer, error) {
+	container := sc.containerFactory(ctx)
+	sc.startSchedule(ctx, container)
+	return sc.containerServerFactory(ctx, container)
 }
 
-func (sc *ServerConfig) serverFactory() (*server.SingleSessionServer, error) {
+func (sc *ServerConfig) containerServerFactory(
+	ctx context.Context,
+	container *controller.SingleControllerContainer,
+) (*server.SingleSessionServer, error) {
 	return server.SingleSessionServerFactory(
-		server.SingleServerContainerOpt(sc.containerFactory),
+		server.SingleServerContainerOpt(func() *controller.SingleControllerContainer {
+			return container
+		}),
+		server.SingleServerClockOpt(sc.clock),
+		server.SingleServerContextOpt(ctx),
 	)
 }
 
-func (sc *ServerConfig) runServerCtx() server.SServerFuncOpt {
+// Wait until pending events and state are written.
+func (sc *ServerConfig) flush(container *controller.SingleControllerContainer) {
+	flushEvents(container)
+	if sc.stateWriter != nil {
+		sc.stateWriter.Wait()
+	}
+}
+
+// Wait until asynchronous subscribers like the history got every event of the
+// container controller, if any.
+func flushEvents(container *controller.SingleControllerContainer) {
+	ctrl, ok := container.GetController().(*controller.PomoController)
+	if !ok {
+		return
+	}
+	ctrl.FlushEvents()
+}
+
+// Load calendar files and watch them. Running work of the container controller
+// is checked again whenever they change.
+func (sc *ServerConfig) startCalendar(ctx context.Context, container *controller.SingleControllerContainer) {
+	if sc.calendar == nil {
+		return
+	}
+	sc.calendar.OnChange = func() {
+		ctrl, ok := container.GetController().(*controller.PomoController)
+		if !ok {
+			return
+		}
+		if err := ctrl.CalendarChanged(timer.ClockOrReal(sc.clock).Now()); err != nil {
+			slog.Error("Cannot check calendar", "err", err)
+		}
+	}
+	sc.calendar.Start(ctx)
+}
+
+// Run scheduled actions on the container controller, created if needed.
+func (sc *ServerConfig) startSchedule(ctx context.Context, container *controller.SingleControllerContainer) {
+	if sc.schedule == nil {
+		return
+	}
+	sc.schedule.Controller = container.CreateController
+	if err := sc.schedule.Start(ctx); err != nil {
+		slog.Error("Cannot start schedule", "err", err)
+		return
+	}
+	next, _ := sc.schedule.Next(timer.ClockOrReal(sc.clock).Now())
+	slog.Info("Schedule started", "next", next.Action, "at", next.At)
+}
+
+// Serve until ctx is done. Closing the listener is a clean exit.
+func serveCtx(ctx context.Context, l net.Listener, s *rpc.Server) error {
+	stop := context.AfterFunc(ctx, func() {
+		if err := l.Close(); err != nil {
+			slog.Error("Cannot close listener", "err", err)
+		}
+	})
+	defer stop()
+
+	err := http.Serve(l, s)
+	if ctx.Err() != nil && errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (sc *ServerConfig) runServerCtx(ctx context.Context) server.SServerFuncOpt {
+	onListen := func(l net.Listener, s *rpc.Server) error {
+		return serveCtx(ctx, l, s)
+	}
+
 	if sc.listenProto == "unix" {
 		return server.SingleServerRpcUnixRegOpt(
 			sc.listenAddress,
 			rpc.NewServer,
-			func(l net.Listener, s *rpc.Server) error {
-
-				exit := make(chan os.Signal, 1)
-				signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
-
-				// TODO: build a more elegant solution...
-				go func() {
-					<-exit
-					err := l.Close()
-					if err != nil {
-						fmt.Println(err)
-					}
-				}()
-
-				return http.Serve(l, s)
-			},
+			onListen,
 		)
 	}
 
@@ -183,16 +707,23 @@ func (sc *ServerConfig) runServerCtx() server.SServerFuncOpt {
 		sc.listenProto,
 		sc.listenAddress,
 		rpc.NewServer,
-		func(l net.Listener, s *rpc.Server) error {
-			return http.Serve(l, s)
-		},
+		onListen,
 	)
 }
 
-// Run appropiate server through http synchronously
+// Run appropiate server through http synchronously. Interrupt and SIGTERM
+// cancel the root context: timers are stopped, hook processes killed and the
+// listener closed. Pending events are written before returning.
 func (sc *ServerConfig) HttpListen() error {
-	run_srv := sc.runServerCtx()
-	srv, err := sc.serverFactory()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	container := sc.containerFactory(ctx)
+	defer sc.flush(container)
+	sc.startSchedule(ctx, container)
+
+	run_srv := sc.runServerCtx(ctx)
+	srv, err := sc.containerServerFactory(ctx, container)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,13 @@
 package config
 
-import "testing"
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FernandoAFS/pomogo/history"
+)
 
 // Extremely basic test. Controlled inputs lead to no error
 func TestServerConfigValidation(t *testing.T) {
@@ -14,11 +21,118 @@ func TestServerConfigValidation(t *testing.T) {
 		longBreakDuration:  15 * 60_000000000,
 	}
 
-	if _, err := sc.controllerFactory(); err != nil {
+	if _, err := sc.controllerFactory(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sc.serverFactory(context.Background()); err != nil {
 		t.Fatal(err)
 	}
+}
+
+// Flowtime work has no minimum unless set. It doesn't wait in overtime.
+func TestServerConfigFlowtime(t *testing.T) {
+
+	sc, err := ServerCmdArgParse("-flowtime", "-flowtime_table", "25m=5m,50m=8m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sc.workDuration != 0 || sc.advancePolicy.ManualAfterWork {
+		t.Fatalf("Unexpected flowtime config %+v", sc)
+	}
+
+	if len(sc.flowtimeTable) != 2 {
+		t.Fatalf("Expected 2 flowtime steps, got %d", len(sc.flowtimeTable))
+	}
 
-	if _, err := sc.serverFactory(); err != nil {
+	sc, err = ServerCmdArgParse("-flowtime", "-work_duration", "10m")
+	if err != nil {
 		t.Fatal(err)
 	}
+
+	if sc.workDuration != 10*time.Minute {
+		t.Fatalf("Work duration is %s, expected 10m", sc.workDuration)
+	}
+}
+
+func TestServerConfigHooks(t *testing.T) {
+	sc, err := ServerCmdArgParse("-hook_timeout", "5s", "-hook_concurrency", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.hookRunner.Timeout != 5*time.Second || sc.hookRunner.MaxConcurrent != 2 {
+		t.Fatalf("Unexpected hook runner %+v", sc.hookRunner)
+	}
+
+	if _, err := ServerCmdArgParse("-hook_concurrency", "0"); err == nil {
+		t.Fatalf("Expected error on no concurrent hooks")
+	}
+	if _, err := ServerCmdArgParse("-hook_timeout", "-1s"); err == nil {
+		t.Fatalf("Expected error on negative timeout")
+	}
+}
+
+func TestServerConfigHookList(t *testing.T) {
+	dir := t.TempDir()
+	sc, err := ServerCmdArgParse(
+		"-event_command", "./all.sh",
+		"-on", "play=./start.sh",
+		"-on", "endofstate,stop=./notify.sh",
+		"-hooks_dir", dir,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sc.hooks) != 4 {
+		t.Fatalf("Expected 4 hooks, got %+v", sc.hooks)
+	}
+	if sc.hooks[0].Command != "./all.sh" || len(sc.hooks[0].Events) != 0 {
+		t.Fatalf("Expected event command on every event, got %+v", sc.hooks[0])
+	}
+	if sc.hooks[2].Command != "./notify.sh" || len(sc.hooks[2].Events) != 2 {
+		t.Fatalf("Unexpected hook %+v", sc.hooks[2])
+	}
+	if sc.hooks[3].Dir != dir {
+		t.Fatalf("Expected hooks dir last, got %+v", sc.hooks[3])
+	}
+
+	if _, err := ServerCmdArgParse("-on", "begin=./start.sh"); err == nil {
+		t.Fatalf("Expected error on unknown event")
+	}
+	if _, err := ServerCmdArgParse("-hooks_dir", dir+"/missing"); err == nil {
+		t.Fatalf("Expected error on missing hooks dir")
+	}
+}
+
+// History is written off the controller. Nothing is lost once flushed.
+func TestServerConfigFlushHistory(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	sc, err := ServerCmdArgParse("-history_file", historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NO CONTROLLER, NOTHING TO FLUSH.
+	container := sc.containerFactory(context.Background())
+	flushEvents(container)
+
+	ctrl := container.CreateController()
+	now := time.Now()
+	if err := ctrl.Play(now); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.Stop(now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	flushEvents(container)
+
+	intervals, err := (&history.FileJournal{Path: historyFile}).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 1 || !intervals[0].Stopped {
+		t.Fatalf("Expected stopped interval on history, got %+v", intervals)
+	}
 }
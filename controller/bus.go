@@ -0,0 +1,274 @@
+// Fan out of controller events to any number of subscribers.
+
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// Subscribers are either synchronous or asynchronous. Synchronous ones run
+// inline while publishing, under the controller lock, so they must be quick
+// and never call the controller back. Asynchronous ones get their own queue
+// and goroutine. Publishing never waits for them. Zero value is ready to use.
+type PomoControllerEventBus struct {
+	seq         uint64
+	lastId      uint64
+	subscribers []*busSubscriber
+
+	// Events queued to asynchronous subscribers but not delivered yet.
+	pending int
+	idle    *sync.Cond
+
+	locker sync.Mutex
+	// KEEPS QUEUES IN SEQUENCE ORDER WHEN PUBLISHING CONCURRENTLY.
+	publishing sync.Mutex
+}
+
+type busSubscriber struct {
+	id   uint64
+	sink func(event PomoControllerEvent)
+	sync bool
+
+	// ASYNC ONLY. UNBOUNDED SO PUBLISH NEVER BLOCKS NOR DROPS.
+	queue  []PomoControllerEvent
+	wake   chan struct{}
+	closed bool
+	locker sync.Mutex
+}
+
+// Run sink on every event from now on, in order, on its own goroutine. Events
+// already queued are still delivered after unsubscribe.
+func (b *PomoControllerEventBus) Subscribe(
+	sink func(event PomoControllerEvent),
+) (unsubscribe func()) {
+	s := &busSubscriber{sink: sink, wake: make(chan struct{}, 1)}
+	b.add(s)
+	go b.deliver(s)
+	return func() { b.remove(s) }
+}
+
+// Run sink on every event from now on while publishing. See
+// PomoControllerEventBus.
+func (b *PomoControllerEventBus) SubscribeSync(
+	sink func(event PomoControllerEvent),
+) (unsubscribe func()) {
+	s := &busSubscriber{sink: sink, sync: true}
+	b.add(s)
+	return func() { b.remove(s) }
+}
+
+// Wait until every event published so far reached every subscriber.
+func (b *PomoControllerEventBus) Flush() {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	b.init()
+	for b.pending > 0 {
+		b.idle.Wait()
+	}
+}
+
+// Give the event the next sequence number and send it to every subscriber.
+func (b *PomoControllerEventBus) Publish(
+	eventType PomoControllerEventType,
+	at time.Time,
+	payload any,
+) PomoControllerEvent {
+	b.publishing.Lock()
+	defer b.publishing.Unlock()
+
+	b.locker.Lock()
+	b.seq++
+	event := PomoControllerEvent{
+		Type:      eventType,
+		Seq:       b.seq,
+		Timestamp: at,
+		Payload:   payload,
+	}
+	subscribers := b.subscribers
+	b.locker.Unlock()
+
+	for _, s := range subscribers {
+		if s.sync {
+			s.sink(event)
+			continue
+		}
+		b.enqueue(s, event)
+	}
+	return event
+}
+
+// Whether anyone listens. Saves building events nobody gets.
+func (b *PomoControllerEventBus) HasSubscribers() bool {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return len(b.subscribers) > 0
+}
+
+// ----------
+// CONTROLLER
+// ----------
+
+// Run sink on every event from now on without holding the controller. See
+// PomoControllerEventBus.Subscribe.
+func (c *PomoController) Subscribe(
+	sink func(event PomoControllerEvent),
+) (unsubscribe func()) {
+	return c.events.Subscribe(sink)
+}
+
+// Run sink on every event from now on under the controller lock. For quick
+// callbacks that must see the event before the controller goes on.
+func (c *PomoController) SubscribeSync(
+	sink func(event PomoControllerEvent),
+) (unsubscribe func()) {
+	return c.events.SubscribeSync(sink)
+}
+
+// Wait until every event so far reached every subscriber.
+func (c *PomoController) FlushEvents() {
+	c.events.Flush()
+}
+
+// -------
+// HELPERS
+// -------
+
+func (b *PomoControllerEventBus) init() {
+	if b.idle == nil {
+		b.idle = sync.NewCond(&b.locker)
+	}
+}
+
+func (b *PomoControllerEventBus) add(s *busSubscriber) {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	b.lastId++
+	s.id = b.lastId
+
+	// COPY ON WRITE. PUBLISH ITERATES ITS OWN SNAPSHOT WITHOUT THE LOCK.
+	subscribers := make([]*busSubscriber, 0, len(b.subscribers)+1)
+	subscribers = append(subscribers, b.subscribers...)
+	b.subscribers = append(subscribers, s)
+}
+
+func (b *PomoControllerEventBus) remove(s *busSubscriber) {
+	b.locker.Lock()
+	subscribers := make([]*busSubscriber, 0, len(b.subscribers))
+	for _, other := range b.subscribers {
+		if other.id != s.id {
+			subscribers = append(subscribers, other)
+		}
+	}
+	b.subscribers = subscribers
+	b.locker.Unlock()
+
+	if s.sync {
+		return
+	}
+
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.signal()
+}
+
+func (b *PomoControllerEventBus) enqueue(s *busSubscriber, event PomoControllerEvent) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	// A CONCURRENT UNSUBSCRIBE MAY HAVE WON THE RACE.
+	if s.closed {
+		return
+	}
+
+	b.locker.Lock()
+	b.init()
+	b.pending++
+	b.locker.Unlock()
+
+	s.queue = append(s.queue, event)
+	s.signal()
+}
+
+// Deliver queued events in order until unsubscribed and drained.
+func (b *PomoControllerEventBus) deliver(s *busSubscriber) {
+	for range s.wake {
+		s.locker.Lock()
+		queue := s.queue
+		s.queue = nil
+		closed := s.closed
+		s.locker.Unlock()
+
+		for _, event := range queue {
+			s.sink(event)
+			b.delivered()
+		}
+
+		if closed {
+			return
+		}
+	}
+}
+
+func (b *PomoControllerEventBus) delivered() {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	b.pending--
+	if b.pending == 0 {
+		b.idle.Broadcast()
+	}
+}
+
+// Wake up the delivery goroutine. Never blocks, a wake up already pending
+// covers every event queued before it is taken.
+func (s *busSubscriber) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ----------------
+// LISTENER ADAPTER
+// ----------------
+
+// Event bus sink calling the listener callback of the event type, if any.
+func (l PomoControllerListener) Sink() func(event PomoControllerEvent) {
+	return func(event PomoControllerEvent) {
+		switch payload := event.Payload.(type) {
+		case error:
+			callSink(l.Error, payload)
+		case PomoControllerEventArgsPlay:
+			callSink(l.Play, payload)
+		case PomoControllerEventArgsStop:
+			callSink(l.Stop, payload)
+		case PomoControllerEventArgsPause:
+			callSink(l.Pause, payload)
+		case PomoControllerEventArgsNextState:
+			callSink(l.NextState, payload)
+		case PomoControllerEventArgsLabel:
+			callSink(l.Label, payload)
+		case PomoControllerEventArgsExtend:
+			callSink(l.Extend, payload)
+		case PomoControllerEventArgsOvertime:
+			callSink(l.Overtime, payload)
+		case PomoControllerEventArgsInterrupt:
+			callSink(l.Interrupt, payload)
+		case PomoControllerEventArgsGoal:
+			callSink(l.Goal, payload)
+		case PomoControllerEventArgsWarning:
+			callSink(l.Warning, payload)
+		}
+	}
+}
+
+// Optional callback.
+func callSink[T any](sink func(T), payload T) {
+	if sink != nil {
+		sink(payload)
+	}
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+// Collects events of an asynchronous subscriber.
+type busRecorder struct {
+	events []PomoControllerEvent
+}
+
+func (r *busRecorder) sink(event PomoControllerEvent) {
+	r.events = append(r.events, event)
+}
+
+func (r *busRecorder) types() []PomoControllerEventType {
+	types := make([]PomoControllerEventType, len(r.events))
+	for i, event := range r.events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+// =====
+// TESTS
+// =====
+
+func TestEventBusFanOut(t *testing.T) {
+	refNow := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+
+	first := &busRecorder{}
+	second := &busRecorder{}
+	syncPlays := 0
+
+	controller, err := mockControllerFactory(
+		timer,
+		sessionFactory(),
+		PomoControllerOptionSubscriber(first.sink),
+		PomoControllerOptionSubscriber(second.sink),
+		PomoControllerOptionPlaySink(func(event PomoControllerEventArgsPlay) {
+			syncPlays++
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Pause(refNow.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Stop(refNow.Add(2 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	// SYNC SUBSCRIBERS ARE DONE BY THE TIME THE ACTION RETURNS.
+	if syncPlays != 1 {
+		t.Fatalf("Expected one synchronous play, got %d", syncPlays)
+	}
+
+	controller.FlushEvents()
+
+	expected := []PomoControllerEventType{
+		PomoControllerEventTypePlay,
+		PomoControllerEventTypePause,
+		PomoControllerEventTypeStop,
+	}
+
+	for _, r := range []*busRecorder{first, second} {
+		types := r.types()
+		if len(types) != len(expected) {
+			t.Fatalf("Expected events %v, got %v", expected, types)
+		}
+		for i := range expected {
+			if types[i] != expected[i] {
+				t.Fatalf("Expected events %v, got %v", expected, types)
+			}
+		}
+		for i, event := range r.events {
+			if event.Seq != uint64(i+1) {
+				t.Fatalf("Expected sequence %d, got %d", i+1, event.Seq)
+			}
+		}
+	}
+
+	play, ok := first.events[0].Payload.(PomoControllerEventArgsPlay)
+	if !ok {
+		t.Fatalf("Unexpected play payload %T", first.events[0].Payload)
+	}
+	if first.events[0].Timestamp != refNow || play.At != refNow {
+		t.Fatalf("Expected play at %s, got %s", refNow, first.events[0].Timestamp)
+	}
+}
+
+// A SLOW SUBSCRIBER NEITHER HOLDS THE CONTROLLER NOR LOSES EVENTS.
+func TestEventBusSlowSubscriber(t *testing.T) {
+	refNow := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+
+	release := make(chan struct{})
+	slow := &busRecorder{}
+
+	controller, err := mockControllerFactory(
+		timer,
+		sessionFactory(),
+		PomoControllerOptionSubscriber(func(event PomoControllerEvent) {
+			<-release
+			slow.sink(event)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			if err := controller.Play(refNow); err != nil {
+				t.Error(err)
+			}
+			if err := controller.Stop(refNow); err != nil {
+				t.Error(err)
+			}
+		}
+		controller.Status()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Controller blocked by a slow subscriber")
+	}
+
+	close(release)
+	controller.FlushEvents()
+
+	if len(slow.events) != 6 {
+		t.Fatalf("Expected 6 events, got %d", len(slow.events))
+	}
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	refNow := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+
+	controller, err := mockControllerFactory(timer, sessionFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &busRecorder{}
+	unsubscribe := controller.Subscribe(r.sink)
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+	controller.FlushEvents()
+	unsubscribe()
+
+	if err := controller.Stop(refNow); err != nil {
+		t.Fatal(err)
+	}
+	controller.FlushEvents()
+
+	if len(r.events) != 1 || r.events[0].Type != PomoControllerEventTypePlay {
+		t.Fatalf("Expected only the play event, got %v", r.types())
+	}
+}
+
+// HOOKS NO LONGER REPLACE LISTENERS SET BEFORE THEM.
+func TestEventBusHookAndListener(t *testing.T) {
+	refNow := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	out := filepath.Join(t.TempDir(), "events")
+	script := filepath.Join(t.TempDir(), "hook.sh")
+	content := "#!/bin/sh\necho \"$POMOGO_EVENT\" >> " + out + "\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	plays := 0
+	runner := &HookRunner{}
+	controller, err := mockControllerFactory(
+		&pomoTimer.MockCbTimer{},
+		sessionFactory(),
+		PomoControllerOptionPlaySink(func(event PomoControllerEventArgsPlay) {
+			plays++
+		}),
+		PomoControllerOptionHookRunner(context.Background(), runner, script),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+	runner.Wait()
+
+	if plays != 1 {
+		t.Fatalf("Expected listener to run once, got %d", plays)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "Play\n" {
+		t.Fatalf("Unexpected hook output %q", b)
+	}
+}
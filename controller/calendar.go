@@ -0,0 +1,93 @@
+// Busy time from calendars. Work intervals are shortened to end before it and
+// never run during it: play is refused and work starting on its own pauses.
+
+package controller
+
+import (
+	"fmt"
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	"time"
+)
+
+// First busy time overlapping work from from to to. Only work is blocked.
+func (c *PomoController) busyDuring(
+	status pomoSession.PomoSessionStatus,
+	from, to time.Time,
+) (PomoControllerBusy, bool) {
+	if c.calendar == nil || status != pomoSession.PomoSessionWork {
+		return PomoControllerBusy{}, false
+	}
+	return c.calendar.Busy(from, to)
+}
+
+// Error if work cannot start at now.
+func (c *PomoController) busyNow(now time.Time, status pomoSession.PomoSessionStatus) error {
+	busy, isBusy := c.busyDuring(status, now, now)
+	if !isBusy {
+		return nil
+	}
+	return fmt.Errorf("%w: %s until %s", ErrCalendarBusy, busy.Reason, busy.End.Format(time.Kitchen))
+}
+
+// Work starting on its own during busy time starts paused with its whole
+// duration. Call with lock.
+func (c *PomoController) busyPause(
+	now time.Time,
+	status pomoSession.PomoSessionStatus,
+	duration time.Duration,
+	busy PomoControllerBusy,
+) {
+	then := now.Add(duration)
+	c.session.SetNextStatus(status)
+	c.endOfState = &then
+	c.stateDuration = duration
+	c.interruptions = PomoControllerInterruptions{}
+	c.pauseAt = &now
+	c.pauseReason = "busy: " + busy.Reason
+	c.busy = &busy
+	c.pauseEvent(now)
+	c.snapshotEvent(now)
+}
+
+// Check running work against busy time again, like after the calendar changed.
+// Busy time started by now pauses it, like work starting on its own during it.
+// Later busy time shortens it.
+func (c *PomoController) CalendarChanged(now time.Time) error {
+	c.locker.Lock()
+	defer c.unlock()
+
+	if c.endOfState == nil || c.pauseAt != nil || c.overtime || c.openEnded() {
+		return nil
+	}
+
+	busy, isBusy := c.busyDuring(c.session.Status(), now, *c.endOfState)
+	if !isBusy {
+		return nil
+	}
+
+	if err := c.cancelTimer(); err != nil {
+		c.errorEvent(err)
+		return err
+	}
+
+	if !busy.Start.After(now) {
+		c.pauseAt = &now
+		c.pauseReason = "busy: " + busy.Reason
+		c.busy = &busy
+		c.pauseEvent(now)
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	// SHORTENED TO END BEFORE BUSY TIME.
+	then := busy.Start
+	if err := c.waitEndOfState(now, then); err != nil {
+		c.errorEvent(err)
+		return err
+	}
+	c.stateDuration -= c.endOfState.Sub(then)
+	c.endOfState = &then
+	c.busy = &busy
+	c.snapshotEvent(now)
+	return nil
+}
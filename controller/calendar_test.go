@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"errors"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+type fakeCalendar []PomoControllerBusy
+
+func (cal fakeCalendar) Busy(from, to time.Time) (PomoControllerBusy, bool) {
+	if !to.After(from) {
+		to = from.Add(time.Nanosecond)
+	}
+	for _, busy := range cal {
+		if busy.Start.Before(to) && busy.End.After(from) {
+			return busy, true
+		}
+	}
+	return PomoControllerBusy{}, false
+}
+
+var calendarRefNow = time.Date(2024, 12, 02, 9, 50, 0, 0, time.UTC)
+
+var meeting = PomoControllerBusy{
+	Start:  calendarRefNow.Add(10 * time.Minute),
+	End:    calendarRefNow.Add(70 * time.Minute),
+	Reason: "Planning",
+}
+
+func calendarControllerFactory(
+	t *testing.T,
+	listener PomoControllerListener,
+) (*PomoController, *pomoTimer.FakeClock) {
+	clock := pomoTimer.NewFakeClock(calendarRefNow)
+	controller := fakeClockControllerFactory(
+		t,
+		clock,
+		PomoControllerOptionCalendar(fakeCalendar{meeting}),
+		PomoControllerOptionListener(listener),
+	)
+	return controller, clock
+}
+
+// =====
+// TESTS
+// =====
+
+func TestControllerCalendar(t *testing.T) {
+	pauses := []PomoControllerEventArgsPause{}
+	controller, clock := calendarControllerFactory(t, PomoControllerListener{
+		Pause: func(event PomoControllerEventArgsPause) {
+			pauses = append(pauses, event)
+		},
+	})
+
+	// WORK ENDS BEFORE THE MEETING.
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	status := controller.Status()
+	if time.Duration(*status.TimeLeft) != 10*time.Minute {
+		t.Fatalf("Expected work shortened to 10m, got %s", time.Duration(*status.TimeLeft))
+	}
+	if status.Busy == nil || status.Busy.Reason != "Planning" {
+		t.Fatalf("Expected busy reason on status, got %+v", status.Busy)
+	}
+
+	// BREAK IS NOT BLOCKED. WORK AFTER IT STARTS PAUSED.
+	clock.Advance(10 * time.Minute)
+	if st := controller.Status().State; st != PomoControllerShortBreak {
+		t.Fatalf("Expected short break, got %s", st)
+	}
+	clock.Advance(5 * time.Minute)
+	status = controller.Status()
+	if status.State != PomoControllerPause || status.PauseReason != "busy: Planning" {
+		t.Fatalf("Expected pause for the meeting, got %s %q", status.State, status.PauseReason)
+	}
+	if len(pauses) != 1 || pauses[0].Reason != "busy: Planning" || pauses[0].CurrentState != PomoControllerWork {
+		t.Fatalf("Expected pause event with reason, got %+v", pauses)
+	}
+
+	// NO WORK UNTIL THE MEETING IS OVER.
+	clock.Advance(15 * time.Minute)
+	if err := controller.Play(clock.Now()); !errors.Is(err, ErrCalendarBusy) {
+		t.Fatalf("Expected ErrCalendarBusy, got %v", err)
+	}
+	clock.AdvanceTo(meeting.End)
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	status = controller.Status()
+	if status.State != PomoControllerWork || time.Duration(*status.TimeLeft) != 25*time.Minute {
+		t.Fatalf("Expected whole work interval after the meeting, got %s", status.State)
+	}
+	if status.Busy != nil {
+		t.Fatalf("Expected no busy time, got %+v", status.Busy)
+	}
+}
+
+func TestControllerCalendarRefusePlay(t *testing.T) {
+	controller, clock := calendarControllerFactory(t, PomoControllerListener{})
+	clock.AdvanceTo(meeting.Start)
+
+	if err := controller.Play(clock.Now()); !errors.Is(err, ErrCalendarBusy) {
+		t.Fatalf("Expected ErrCalendarBusy, got %v", err)
+	}
+	if st := controller.Status().State; st != PomoControllerStopped {
+		t.Fatalf("Expected stopped, got %s", st)
+	}
+}
+
+// RESUMED WORK IS SHORTENED TOO.
+func TestControllerCalendarResume(t *testing.T) {
+	controller, clock := calendarControllerFactory(t, PomoControllerListener{})
+	clock.AdvanceTo(meeting.End)
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Pause(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if st := controller.Status(); st.PauseReason != "" {
+		t.Fatalf("Expected no reason on asked pause, got %q", st.PauseReason)
+	}
+
+	// NEXT DAY, TEN MINUTES BEFORE THE SAME TIME MEETING.
+	next := fakeCalendar{{
+		Start:  meeting.Start.AddDate(0, 0, 1),
+		End:    meeting.End.AddDate(0, 0, 1),
+		Reason: "Planning",
+	}}
+	controller.calendar = next
+	clock.AdvanceTo(calendarRefNow.AddDate(0, 0, 1))
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	tick := controller.Tick(clock.Now())
+	if time.Duration(tick.TimeLeft) != 10*time.Minute || time.Duration(tick.Elapsed) != 0 {
+		t.Fatalf("Expected 10m left and nothing elapsed, got %+v", tick)
+	}
+}
+
+// WORK CUT SHORT BY A MEETING IS NOT A POMODORO.
+func TestControllerCalendarShortened(t *testing.T) {
+	nextStates := []PomoControllerEventArgsNextState{}
+	controller, clock := calendarControllerFactory(t, PomoControllerListener{
+		NextState: func(event PomoControllerEventArgsNextState) {
+			nextStates = append(nextStates, event)
+		},
+	})
+	controller.goal = &DailyGoal{Target: 8, Location: time.UTC}
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(10 * time.Minute)
+	if len(nextStates) != 1 || !nextStates[0].Shortened || nextStates[0].TimeSpent != 10*time.Minute {
+		t.Fatalf("Expected shortened work to end, got %+v", nextStates)
+	}
+	if done := controller.goal.Progress(clock.Now()).Done; done != 0 {
+		t.Fatalf("Goal done is %d, expected shortened work not to count", done)
+	}
+
+	// WHOLE WORK AFTER THE MEETING COUNTS.
+	clock.AdvanceTo(meeting.End)
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(25 * time.Minute)
+	if len(nextStates) != 3 || nextStates[1].Shortened || nextStates[2].Shortened {
+		t.Fatalf("Expected only the first work shortened, got %+v", nextStates)
+	}
+	if done := controller.goal.Progress(clock.Now()).Done; done != 1 {
+		t.Fatalf("Goal done is %d, expected 1", done)
+	}
+}
+
+// MEETINGS ADDED WHILE WORKING ARE SEEN ONCE THE CALENDAR CHANGES.
+func TestControllerCalendarChanged(t *testing.T) {
+	pauses := []PomoControllerEventArgsPause{}
+	controller, clock := calendarControllerFactory(t, PomoControllerListener{
+		Pause: func(event PomoControllerEventArgsPause) {
+			pauses = append(pauses, event)
+		},
+	})
+	clock.AdvanceTo(meeting.End)
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTHING NEW.
+	if err := controller.CalendarChanged(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if left := time.Duration(*controller.Status().TimeLeft); left != 25*time.Minute {
+		t.Fatalf("Expected 25m left, got %s", left)
+	}
+
+	review := PomoControllerBusy{
+		Start:  meeting.End.Add(10 * time.Minute),
+		End:    meeting.End.Add(40 * time.Minute),
+		Reason: "Review",
+	}
+	controller.calendar = fakeCalendar{meeting, review}
+	clock.Advance(time.Minute)
+	if err := controller.CalendarChanged(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	status := controller.Status()
+	if time.Duration(*status.TimeLeft) != 9*time.Minute || status.Busy == nil || status.Busy.Reason != "Review" {
+		t.Fatalf("Expected work shortened to the review, got %+v", status)
+	}
+
+	// ENDS BY TIMER BEFORE THE REVIEW.
+	clock.AdvanceTo(review.Start)
+	if st := controller.Status().State; st != PomoControllerShortBreak {
+		t.Fatalf("Expected short break, got %s", st)
+	}
+
+	// WORK AFTER THE BREAK WAITS FOR THE REVIEW. BUSY RIGHT NOW PAUSES.
+	clock.AdvanceTo(review.End)
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	urgent := PomoControllerBusy{
+		Start:  clock.Now().Add(-time.Minute),
+		End:    clock.Now().Add(time.Hour),
+		Reason: "Incident",
+	}
+	controller.calendar = fakeCalendar{urgent}
+	if err := controller.CalendarChanged(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	status = controller.Status()
+	if status.State != PomoControllerPause || status.PauseReason != "busy: Incident" {
+		t.Fatalf("Expected pause for the incident, got %s %q", status.State, status.PauseReason)
+	}
+	if len(pauses) == 0 || pauses[len(pauses)-1].Reason != "busy: Incident" {
+		t.Fatalf("Expected pause event with reason, got %+v", pauses)
+	}
+}
@@ -0,0 +1,78 @@
+// Cancellation. The root context is given on creation and stops every timer
+// when done so nothing is left running on shutdown. Actions don't block, the
+// context of an action only decides whether it starts at all.
+
+package controller
+
+import (
+	"context"
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	"time"
+)
+
+// Root context. Background if none was given.
+func (c *PomoController) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// Error of the action or root context, whatever is done first.
+func (c *PomoController) ctxErr(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.context().Err()
+}
+
+// Cancel timers and warnings on root context done. The session is kept as it
+// is so a saved state is resumed on the next start.
+func (c *PomoController) shutdown() {
+	c.locker.Lock()
+	defer c.unlock()
+
+	if err := c.cancelTimer(); err != nil {
+		c.errorEvent(err)
+	}
+}
+
+// -------------------------
+// ACTIONS WITHOUT A CONTEXT
+// -------------------------
+
+func (c *PomoController) Pause(now time.Time) error {
+	return c.PauseCtx(context.Background(), now)
+}
+
+func (c *PomoController) Play(now time.Time) error {
+	return c.PlayCtx(context.Background(), now)
+}
+
+func (c *PomoController) PlayLabel(now time.Time, label SessionLabel) error {
+	return c.PlayLabelCtx(context.Background(), now, label)
+}
+
+func (c *PomoController) Skip(now time.Time) error {
+	return c.SkipCtx(context.Background(), now)
+}
+
+func (c *PomoController) SkipTo(now time.Time, status pomoSession.PomoSessionStatus) error {
+	return c.SkipToCtx(context.Background(), now, status)
+}
+
+func (c *PomoController) Stop(now time.Time) error {
+	return c.StopCtx(context.Background(), now)
+}
+
+func (c *PomoController) Extend(now time.Time, delta time.Duration) error {
+	return c.ExtendCtx(context.Background(), now, delta)
+}
+
+func (c *PomoController) Interrupt(now time.Time, kind PomoInterruptionKind, note string) error {
+	return c.InterruptCtx(context.Background(), now, kind, note)
+}
+
+func (c *PomoController) Label(now time.Time, label SessionLabel) error {
+	return c.LabelCtx(context.Background(), now, label)
+}
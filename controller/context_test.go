@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+// Wait for the root context to be handled, on its own goroutine.
+func waitNoTimer(t *testing.T, timer *checkTimer) {
+	deadline := time.Now().Add(time.Second)
+	for timer.isPending() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected timer to be cancelled with the root context")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// =====
+// TESTS
+// =====
+
+func TestControllerActionCtx(t *testing.T) {
+	timer := &checkTimer{}
+	controller, err := mockControllerFactory(timer, sessionFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := controller.PlayCtx(ctx, now); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if st := controller.Status().State; st != PomoControllerStopped {
+		t.Fatalf("Expected stopped controller, got %s", st)
+	}
+
+	// OTHER CONTEXTS ARE NOT AFFECTED.
+	if err := controller.PlayCtx(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.PauseCtx(ctx, now); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if !timer.isPending() {
+		t.Fatalf("Expected timer to keep running")
+	}
+}
+
+func TestControllerRootCtx(t *testing.T) {
+	timer := &checkTimer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	controller, err := mockControllerFactory(
+		timer,
+		sessionFactory(),
+		PomoControllerOptionContext(ctx),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	if err := controller.Play(now); err != nil {
+		t.Fatal(err)
+	}
+	ticks := make(chan PomoControllerTick, 10)
+	if _, err := controller.SubscribeTicks(time.Second, func(tick PomoControllerTick) {
+		ticks <- tick
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	waitNoTimer(t, timer)
+
+	// STATE IS KEPT FOR THE NEXT START.
+	if st := controller.Status().State; st != PomoControllerWork {
+		t.Fatalf("Expected work state to be kept, got %s", st)
+	}
+	if err := controller.Skip(now); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := controller.SubscribeTicks(time.Second, func(PomoControllerTick) {}); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(timer.violations) > 0 {
+		t.Fatalf("Timer misuse %v", timer.violations)
+	}
+}
+
+// HOOK PROCESSES ARE KILLED WITH THE ROOT CONTEXT.
+func TestControllerHookCtx(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 30\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := &HookRunner{}
+	controller, err := mockControllerFactory(
+		&checkTimer{},
+		sessionFactory(),
+		PomoControllerOptionContext(ctx),
+		PomoControllerOptionHookRunner(ctx, runner, script),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := controller.Play(start); err != nil {
+		t.Fatal(err)
+	}
+	runner.Wait()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Expected hook to be killed on cancel, took %s", elapsed)
+	}
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	pomoSession "github.com/FernandoAFS/pomogo/session"
 	pomoTimer "github.com/FernandoAFS/pomogo/timer"
 	"sync"
@@ -19,17 +21,70 @@ type PomoController struct {
 	session         pomoSession.PomoSessionIface
 	timer           pomoTimer.PomoTimerIface
 	durationFactory pomoSession.SessionStateDurationFactory
+	// Time source for status. Real clock if nil.
+	clock pomoTimer.Clock
 
-	errorSink      func(err error)
-	playEventSink  func(event PomoControllerEventArgsPlay)
-	stopEventSink  func(event PomoControllerEventArgsStop)
-	pauseEventSink func(event PomoControllerEventArgsPause)
-
-	// RUN ON END OF STATE TIME OR ON SKIP STATES
-	endOfStateEventSink func(event PomoControllerEventArgsNextState)
+	// Every event goes through the bus, to any number of subscribers.
+	events       PomoControllerEventBus
+	snapshotSink func(snapshot PomoControllerSnapshot)
+	// Time of the last change not sent to snapshotSink yet. Sent once the
+	// action is done. Nil if there is none.
+	snapshotAt *time.Time
 
 	pauseAt    *time.Time
 	endOfState *time.Time
+	// Why it paused. Empty for pauses asked for.
+	pauseReason string
+
+	// Manual advance waits for play at the end of state. Meanwhile the
+	// controller is in overtime, counting up from end of state.
+	advancePolicy PomoControllerAdvancePolicy
+	overtime      bool
+	// Current state was counted for the goal when its overtime began.
+	goalCounted bool
+
+	// Open ended work counts up with no end of its own until play or skip
+	// starts the break. Work duration is only a minimum then.
+	openWork bool
+
+	// Time worked in the last work interval and who needs it. Nil if nobody.
+	worked     time.Duration
+	workedSink PomoControllerWorkedIface
+
+	// Duration of the current state. Computed once when the state starts.
+	stateDuration time.Duration
+
+	label SessionLabel
+
+	// Interruptions of the current state. The work interval starts over once
+	// they exceed the threshold. Zero threshold disables it.
+	interruptions PomoControllerInterruptions
+	voidThreshold int
+
+	// Optional daily goal. Nil if disabled.
+	goal *DailyGoal
+
+	// Only applies to timers able to notice clock jumps.
+	jumpPolicy PomoControllerJumpPolicy
+
+	// Identifies the current timer wait. Callbacks of cancelled waits may
+	// already be waiting for the lock and are dropped if it changed.
+	waitSeq uint64
+
+	// Warnings before the end of state, on their own timer.
+	warnings     PomoControllerWarnings
+	warningTimer pomoTimer.PomoTimerIface
+
+	// Root context. Timers are cancelled once done. Never done if nil.
+	ctx context.Context
+
+	// Source of the next scheduled action for status. Nil if none.
+	schedule PomoControllerScheduleIface
+
+	// Source of busy time blocking work. Nil if none.
+	calendar PomoControllerCalendarIface
+	// Busy time that shortened or paused the current interval.
+	busy *PomoControllerBusy
 
 	locker sync.Mutex
 }
@@ -43,51 +98,118 @@ func (c *PomoController) Status() PomoControllerStatus {
 	c.locker.Lock()
 	defer c.locker.Unlock()
 
-	if c.endOfState == nil {
-		return PomoControllerStatus{
-			State:          PomoControllerStopped,
-			TimeLeft:       nil,
-			PausedAt:       nil,
-			WorkedSessions: 0,
+	status := PomoControllerStatus{
+		State: PomoControllerStopped,
+		Label: c.statusLabel(),
+	}
+
+	now := pomoTimer.ClockOrReal(c.clock).Now()
+
+	if c.goal != nil {
+		progress := c.goal.Progress(now)
+		status.Goal = &progress
+	}
+
+	if c.schedule != nil {
+		if next, ok := c.schedule.Next(now); ok {
+			status.Scheduled = &next
 		}
 	}
 
-	workedSessions := c.session.CompletedWorkSessions()
+	if c.endOfState == nil {
+		return status
+	}
 
-	if c.pauseAt != nil {
-		return PomoControllerStatus{
-			State:          PomoControllerPause,
-			TimeLeft:       nil,
-			PausedAt:       c.pauseAt,
-			WorkedSessions: workedSessions,
+	status.WorkedSessions = c.session.CompletedWorkSessions()
+	status.Interruptions = c.interruptions
+	if c.busy != nil {
+		busy := *c.busy
+		status.Busy = &busy
+	}
+
+	if c.openEnded() {
+		from := now
+		if c.pauseAt != nil {
+			from = *c.pauseAt
 		}
+		elapsed := StatusDuration(c.elapsed(from))
+		status.Elapsed = &elapsed
+	}
+
+	if c.pauseAt != nil {
+		status.State = PomoControllerPause
+		status.PausedAt = c.pauseAt
+		status.PauseReason = c.pauseReason
+		return status
+	}
+
+	if c.overtime {
+		overtime := StatusDuration(now.Sub(*c.endOfState))
+		status.State = PomoControllerOvertime
+		status.Overtime = &overtime
+		return status
 	}
 
-	now := time.Now()
 	timeLeft := StatusDuration(c.endOfState.Sub(now))
-	return PomoControllerStatus{
-		State:          SessionToControllerState(c.session.Status()),
-		TimeLeft:       &timeLeft,
-		PausedAt:       nil,
-		WorkedSessions: workedSessions,
+	status.State = SessionToControllerState(c.session.Status())
+	// OPEN ENDED WORK HAS NOTHING LEFT PAST ITS MINIMUM.
+	if timeLeft > 0 || !c.openEnded() {
+		status.TimeLeft = &timeLeft
+	}
+	return status
+}
+
+// Whether the current state is open ended work. Call with lock.
+func (c *PomoController) openEnded() bool {
+	return c.endOfState != nil && c.isOpen(c.session.Status())
+}
+
+// Whether status counts up with no end of its own.
+func (c *PomoController) isOpen(status pomoSession.PomoSessionStatus) bool {
+	return c.openWork && status == pomoSession.PomoSessionWork
+}
+
+// Time spent on the current state at from. Keeps growing past the end of open
+// ended work.
+func (c *PomoController) elapsed(from time.Time) time.Duration {
+	return c.stateDuration - c.endOfState.Sub(from)
+}
+
+// Time spent on and left of the current state at from. Open ended work has
+// nothing left past its minimum.
+func (c *PomoController) stateTimes(from time.Time) (time.Duration, time.Duration) {
+	timeLeft := c.endOfState.Sub(from)
+	if timeLeft < 0 && c.openEnded() {
+		timeLeft = 0
 	}
+	return c.elapsed(from), timeLeft
+}
+
+// Copy of the label for status report. Nil if there is no label.
+func (c *PomoController) statusLabel() *SessionLabel {
+	if c.label.IsEmpty() {
+		return nil
+	}
+	label := c.label
+	return &label
 }
 
 // --------------
 // EVENT EMITTING
 // --------------
 
-// Optional error event wrapper
+// Error event wrapper
 func (c *PomoController) errorEvent(err error) {
-	if c.errorSink == nil {
+	if !c.events.HasSubscribers() {
 		return
 	}
-	c.errorSink(err)
+	now := pomoTimer.ClockOrReal(c.clock).Now()
+	c.events.Publish(PomoControllerEventTypeError, now, err)
 }
 
-// Optional play event wrapper
+// Play event wrapper
 func (c *PomoController) playEvent(now time.Time) {
-	if c.playEventSink == nil {
+	if !c.events.HasSubscribers() {
 		return
 	}
 
@@ -98,70 +220,195 @@ func (c *PomoController) playEvent(now time.Time) {
 		At:                   now,
 		CurrentState:         SessionToControllerState(status),
 		NextState:            SessionToControllerState(nextStatus),
-		CurrentStateDuration: c.durationFactory(status),
+		CurrentStateDuration: c.stateDuration,
+		WorkedSessions:       c.session.CompletedWorkSessions(),
+		Label:                c.label,
 	}
 
-	c.playEventSink(playEvent)
+	c.events.Publish(PomoControllerEventTypePlay, now, playEvent)
 }
 
-// Optional play event wrapper
+// Stop event wrapper
 func (c *PomoController) stopEvent(now time.Time) {
-	if c.stopEventSink == nil {
+	if !c.events.HasSubscribers() {
 		return
 	}
 
 	status := c.session.Status()
-	duration := c.durationFactory(status)
-	timeLeft := c.endOfState.Sub(now)
-	timeSpent := duration - timeLeft
+	timeSpent, timeLeft := c.stateTimes(now)
 
 	stopEvent := PomoControllerEventArgsStop{
-		At:           now,
-		CurrentState: SessionToControllerState(status),
-		TimeSpent:    timeSpent,
-		TimeLeft:     timeLeft,
+		At:             now,
+		CurrentState:   SessionToControllerState(status),
+		TimeSpent:      timeSpent,
+		TimeLeft:       timeLeft,
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+		Overtime:       c.overtimeAmount(now),
 	}
 
-	c.stopEventSink(stopEvent)
+	c.events.Publish(PomoControllerEventTypeStop, now, stopEvent)
 }
 
 func (c *PomoController) pauseEvent(now time.Time) {
-	if c.pauseEventSink == nil {
+	if !c.events.HasSubscribers() {
 		return
 	}
 
 	status := c.session.Status()
-	duration := c.durationFactory(status)
-	timeLeft := c.endOfState.Sub(now)
-	timeSpent := duration - timeLeft
+	timeSpent, timeLeft := c.stateTimes(now)
 
 	pauseEvent := PomoControllerEventArgsPause{
-		At:           now,
-		CurrentState: SessionToControllerState(status),
-		TimeSpent:    timeSpent,
-		TimeLeft:     timeLeft,
+		At:             now,
+		CurrentState:   SessionToControllerState(status),
+		TimeSpent:      timeSpent,
+		TimeLeft:       timeLeft,
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+		Reason:         c.pauseReason,
 	}
 
-	c.pauseEventSink(pauseEvent)
+	c.events.Publish(PomoControllerEventTypePause, now, pauseEvent)
 }
 
-func (c *PomoController) endOfStateEvent(now time.Time) {
-	if c.endOfStateEventSink == nil {
+func (c *PomoController) endOfStateEvent(now time.Time, skipped bool) {
+	if !c.events.HasSubscribers() {
 		return
 	}
 
 	status := c.session.Status()
 	nextStatus := c.session.GetNextStatus()
-	timeLeft := c.endOfState.Sub(now)
+	from := now
+	if c.pauseAt != nil {
+		from = *c.pauseAt
+	}
+	timeSpent, timeLeft := c.stateTimes(from)
 
 	nextStateEvent := PomoControllerEventArgsNextState{
-		At:           now,
-		CurrentState: SessionToControllerState(status),
-		NextState:    SessionToControllerState(nextStatus),
-		TimeLeft:     timeLeft,
+		At:             now,
+		CurrentState:   SessionToControllerState(status),
+		NextState:      SessionToControllerState(nextStatus),
+		TimeSpent:      timeSpent,
+		TimeLeft:       timeLeft,
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+		Skipped:        skipped,
+		Overtime:       c.overtimeAmount(now),
+		Shortened:      c.shortened(skipped),
+	}
+
+	c.events.Publish(PomoControllerEventTypeNextState, now, nextStateEvent)
+}
+
+// End of state that happened while nobody was watching. See catchUpPlan.
+func (c *PomoController) caughtUpEvent(event PomoControllerEventArgsNextState) {
+	if !c.events.HasSubscribers() {
+		return
+	}
+	c.events.Publish(PomoControllerEventTypeNextState, event.At, event)
+}
+
+func (c *PomoController) overtimeEvent(now time.Time) {
+	if !c.events.HasSubscribers() {
+		return
+	}
+
+	status := c.session.Status()
+	nextStatus := c.session.GetNextStatus()
+
+	overtimeEvent := PomoControllerEventArgsOvertime{
+		At:             now,
+		CurrentState:   SessionToControllerState(status),
+		NextState:      SessionToControllerState(nextStatus),
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+	}
+
+	c.events.Publish(PomoControllerEventTypeOvertime, now, overtimeEvent)
+}
+
+func (c *PomoController) interruptEvent(
+	now time.Time,
+	kind PomoInterruptionKind,
+	note string,
+	interruptions PomoControllerInterruptions,
+	voided bool,
+) {
+	if !c.events.HasSubscribers() {
+		return
+	}
+
+	interruptEvent := PomoControllerEventArgsInterrupt{
+		At:             now,
+		CurrentState:   SessionToControllerState(c.session.Status()),
+		Kind:           kind,
+		Note:           note,
+		Interruptions:  interruptions,
+		Voided:         voided,
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+	}
+
+	c.events.Publish(PomoControllerEventTypeInterrupt, now, interruptEvent)
+}
+
+func (c *PomoController) goalEvent(now time.Time) {
+	if !c.events.HasSubscribers() {
+		return
+	}
+
+	goalEvent := PomoControllerEventArgsGoal{
+		At:             now,
+		Progress:       c.goal.Progress(now),
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+	}
+
+	c.events.Publish(PomoControllerEventTypeGoal, now, goalEvent)
+}
+
+func (c *PomoController) labelEvent(now time.Time) {
+	if !c.events.HasSubscribers() {
+		return
+	}
+
+	state := PomoControllerStopped
+	if c.endOfState != nil {
+		state = SessionToControllerState(c.session.Status())
+	}
+
+	labelEvent := PomoControllerEventArgsLabel{
+		At:             now,
+		CurrentState:   state,
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+	}
+
+	c.events.Publish(PomoControllerEventTypeLabel, now, labelEvent)
+}
+
+func (c *PomoController) extendEvent(now time.Time, delta time.Duration) {
+	if !c.events.HasSubscribers() {
+		return
+	}
+
+	// TIME LEFT IS FROZEN WHILE PAUSED.
+	from := now
+	if c.pauseAt != nil {
+		from = *c.pauseAt
+	}
+	_, timeLeft := c.stateTimes(from)
+
+	extendEvent := PomoControllerEventArgsExtend{
+		At:             now,
+		CurrentState:   SessionToControllerState(c.session.Status()),
+		Delta:          delta,
+		TimeLeft:       timeLeft,
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
 	}
 
-	c.endOfStateEventSink(nextStateEvent)
+	c.events.Publish(PomoControllerEventTypeExtend, now, extendEvent)
 }
 
 // ------------------
@@ -169,32 +416,77 @@ func (c *PomoController) endOfStateEvent(now time.Time) {
 // ------------------
 
 // Freeze timer in time
-func (c *PomoController) Pause(now time.Time) error {
+func (c *PomoController) PauseCtx(ctx context.Context, now time.Time) error {
 	c.locker.Lock()
-	defer c.locker.Unlock()
+	defer c.unlock()
+
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
 
 	if c.pauseAt != nil {
 		c.errorEvent(ErrPausedTimer)
 		return ErrPausedTimer
 	}
-	c.pauseAt = &now
 
-	if err := c.timer.Cancel(); err != nil {
+	if c.endOfState == nil {
+		c.errorEvent(ErrStoppedTimer)
+		return ErrStoppedTimer
+	}
+
+	if c.overtime {
+		c.errorEvent(ErrOvertimeTimer)
+		return ErrOvertimeTimer
+	}
+
+	if err := c.cancelTimer(); err != nil {
 		c.errorEvent(err)
 		return err
 	}
+	c.pauseAt = &now
+	c.pauseReason = ""
 	c.pauseEvent(now)
+	c.snapshotEvent(now)
 	return nil
 }
 
 // Start paused timer or resume paused timer
-func (c *PomoController) Play(now time.Time) error {
+func (c *PomoController) PlayCtx(ctx context.Context, now time.Time) error {
+	return c.PlayLabelCtx(ctx, now, SessionLabel{})
+}
+
+// Same as PlayCtx attaching a non empty label to the interval it starts or
+// resumes. The label is left as it was if play fails.
+func (c *PomoController) PlayLabelCtx(ctx context.Context, now time.Time, label SessionLabel) error {
 	c.locker.Lock()
-	defer c.locker.Unlock()
+	defer c.unlock()
 
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
+
+	prev := c.label
+	if err := c.play(now, label); err != nil {
+		c.label = prev
+		return err
+	}
+
+	if !label.IsEmpty() {
+		c.labelEvent(now)
+	}
+	return nil
+}
+
+// Call with lock. Label is set right before the interval starts.
+func (c *PomoController) play(now time.Time, label SessionLabel) error {
 	if c.endOfState == nil {
 		c.session.Reset()
 		status := c.session.Status()
+		if err := c.busyNow(now, status); err != nil {
+			c.errorEvent(err)
+			return err
+		}
+		c.relabel(label)
 		if err := c.runTimer(now, status); err != nil {
 			c.errorEvent(err)
 			return err
@@ -204,94 +496,320 @@ func (c *PomoController) Play(now time.Time) error {
 	}
 
 	if c.pauseAt != nil {
+		if err := c.busyNow(now, c.session.Status()); err != nil {
+			c.errorEvent(err)
+			return err
+		}
+		c.relabel(label)
 		return c.resume(now)
 	}
 
+	// OPEN ENDED WORK IS OVER ONCE THE BREAK IS ASKED FOR.
+	if c.overtime || c.openEnded() {
+		return c.advance(now, label)
+	}
+
 	c.errorEvent(ErrRunningTimer)
 	return ErrRunningTimer
 }
 
+// Set label unless it's empty. Call with lock.
+func (c *PomoController) relabel(label SessionLabel) {
+	if label.IsEmpty() {
+		return
+	}
+	c.label = label
+}
+
 // Run on a paused timer
 func (c *PomoController) resume(now time.Time) error {
 
 	stateTimeLeft := c.endOfState.Sub(*c.pauseAt)
 	then := now.Add(stateTimeLeft)
 
+	// NOTHING TO WAIT FOR. KEEPS COUNTING UP FROM THE PAUSE.
+	if c.openEnded() {
+		c.busy = nil
+		c.pauseAt = nil
+		c.endOfState = &then
+		c.playEvent(now)
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	// SHORTENED TO END BEFORE BUSY TIME.
+	busy, isBusy := c.busyDuring(c.session.Status(), now, then)
+	if isBusy {
+		then = busy.Start
+	}
+
+	if err := c.waitEndOfState(now, then); err != nil {
+		c.errorEvent(err)
+		return err
+	}
+
+	c.busy = nil
+	if isBusy {
+		c.stateDuration -= stateTimeLeft - then.Sub(now)
+		c.busy = &busy
+	}
+	c.pauseAt = nil
+	c.endOfState = &then
+	c.playEvent(now)
+	c.snapshotEvent(now)
+	return nil
+}
+
+// Wait until then to move to the next state. Warnings of the current state
+// are scheduled too.
+func (c *PomoController) waitEndOfState(now, then time.Time) error {
+	if err := c.waitTimer(now, then); err != nil {
+		return err
+	}
+	c.waitWarning(now, then)
+	return nil
+}
+
+// Wait without warnings. Nothing is waited once the root context is done.
+func (c *PomoController) waitTimer(now, then time.Time) error {
+	if err := c.context().Err(); err != nil {
+		return err
+	}
+
+	c.waitSeq++
+	seq := c.waitSeq
+
 	cb := func() {
-		nextStatus := c.session.GetNextStatus()
-		if err := c.runTimer(then, nextStatus); err != nil {
+		if err := c.nextTimer(seq, then); err != nil {
 			c.errorEvent(err)
 		}
 	}
 
-	if err := c.timer.WaitCb(stateTimeLeft, cb); err != nil {
-		c.errorEvent(err)
+	jumpTimer, ok := c.timer.(pomoTimer.PomoJumpTimerIface)
+	if !ok {
+		return c.timer.WaitCb(then.Sub(now), cb)
+	}
+
+	onJump := func(jump pomoTimer.TimerJump) {
+		if err := c.clockJump(seq, jump, then); err != nil {
+			c.errorEvent(err)
+		}
+	}
+	return jumpTimer.WaitJumpCb(then.Sub(now), cb, onJump)
+}
+
+// Cancel end of state wait and pending warnings.
+func (c *PomoController) cancelTimer() error {
+	c.cancelWarning()
+	c.waitSeq++
+
+	// FIRED BUT THE CALLBACK IS WAITING FOR THE LOCK. IT WILL BE DROPPED.
+	if err := c.timer.Cancel(); err != nil && !errors.Is(err, pomoTimer.ErrTimerNotWaited) {
 		return err
 	}
+	return nil
+}
 
-	c.pauseAt = nil
-	eos := now.Add(stateTimeLeft)
-	c.endOfState = &eos
+// Callback of an old wait or of a wait cancelled after firing. Call with lock.
+func (c *PomoController) staleWait(seq uint64) bool {
+	return seq != c.waitSeq || c.pauseAt != nil || c.endOfState == nil || c.overtime
+}
+
+// Run when the timer waiting for then notices a clock jump. The wait is over
+// so a new one is needed unless the controller pauses.
+func (c *PomoController) clockJump(seq uint64, jump pomoTimer.TimerJump, then time.Time) error {
+	c.locker.Lock()
+	defer c.unlock()
+
+	// STALE WAIT. THE STATE CHANGED WHILE THE JUMP WAS REPORTED.
+	if c.staleWait(seq) {
+		return nil
+	}
+
+	now := jump.To
+
+	if c.jumpPolicy == PomoControllerJumpPause {
+		pauseAt := jump.From
+		if pauseAt.After(then) {
+			pauseAt = then
+		}
+		c.pauseAt = &pauseAt
+		c.pauseReason = "clock jump"
+		c.cancelWarning()
+		c.pauseEvent(pauseAt)
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	// NOT OVER YET. KEEP WAITING FOR THE SAME END OF STATE.
+	if then.After(now) {
+		return c.waitEndOfState(now, then)
+	}
+
+	if c.jumpPolicy == PomoControllerJumpFire {
+		return c.endState(now)
+	}
+
+	if err := c.catchUp(now); err != nil {
+		c.stopped(now)
+		return ErrJumpExpired
+	}
+
+	if c.overtime {
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	if !c.openEnded() {
+		if err := c.waitEndOfState(now, *c.endOfState); err != nil {
+			return err
+		}
+	}
+	// CAUGHT UP STATES WERE NOT SEEN. THE ONE RUNNING IS SEEN FROM NOW ON.
 	c.playEvent(now)
+	c.snapshotEvent(now)
 	return nil
 }
 
 // call at the end of state timer event
-func (c *PomoController) nextTimer(now time.Time) error {
+func (c *PomoController) nextTimer(seq uint64, now time.Time) error {
 	// RECURSIVE CALLING THE TIMER MUST BE DONE IN A THREAD SAFE WAY.
 	c.locker.Lock()
-	defer c.locker.Unlock()
+	defer c.unlock()
 
-	// THIS TWO CHECK SHOULDN'T HAPPEN. IF THERE IS ANY EDGE RACE CONDITION
-	// IT'S BETTER TO STOP HERE.
-
-	if c.pauseAt != nil {
-		c.errorEvent(ErrPausedTimer)
-		return ErrPausedTimer
+	// PAUSE, SKIP OR STOP RAN BETWEEN TIMER EVENT AND LOCK CAPTURE.
+	if c.staleWait(seq) {
+		return nil
 	}
 
-	if c.endOfState == nil {
-		c.errorEvent(ErrStoppedTimer)
-		return ErrStoppedTimer
+	return c.endState(now)
+}
+
+// Current state is over. Wait in overtime or start the next one.
+func (c *PomoController) endState(now time.Time) error {
+	if c.advancePolicy.IsManual(c.session.Status()) {
+		c.overtime = true
+		c.overtimeEvent(now)
+		// WORK IS DONE BY NOW. WHAT COMES NEXT DOESN'T CHANGE THAT.
+		c.countGoal(now)
+		c.snapshotEvent(now)
+		return nil
 	}
 
+	// Fire before running next timer so the event reports the state that
+	// ended, same as skip.
 	nextStatus := c.session.GetNextStatus()
-	if err := c.runTimer(now, nextStatus); err != nil {
-		return err
+	c.stateEnded(now, false)
+	return c.runTimer(now, nextStatus)
+}
+
+// Leave overtime or open ended work and start next state.
+func (c *PomoController) advance(now time.Time, label SessionLabel) error {
+	nextStatus := c.session.GetNextStatus()
+	c.stateEnded(now, false)
+	c.overtime = false
+	c.relabel(label)
+	return c.runTimer(now, nextStatus)
+}
+
+// Report end of current state and count it for the goal if it was full work.
+func (c *PomoController) stateEnded(now time.Time, skipped bool) {
+	if c.session.Status() == pomoSession.PomoSessionWork {
+		from := now
+		if c.pauseAt != nil {
+			from = *c.pauseAt
+		}
+		c.setWorked(c.elapsed(from))
+	}
+	c.endOfStateEvent(now, skipped)
+	if !skipped {
+		c.countGoal(now)
 	}
+}
 
-	// Fire only next status event if the next state is coming.
-	c.endOfStateEvent(now)
-	return nil
+// Keep time worked in the last work interval for the break that follows.
+func (c *PomoController) setWorked(worked time.Duration) {
+	c.worked = worked
+	if c.workedSink != nil {
+		c.workedSink.SetWorked(worked)
+	}
+}
+
+// Count current state for the goal if it was full work. Once per state.
+func (c *PomoController) countGoal(now time.Time) {
+	if c.goal == nil || c.goalCounted || c.session.Status() != pomoSession.PomoSessionWork {
+		return
+	}
+	if c.shortened(false) {
+		return
+	}
+	c.goalCounted = true
+	if c.goal.Record(now) {
+		c.goalEvent(now)
+	}
+}
+
+// Whether the state ending was work cut to end before busy time.
+func (c *PomoController) shortened(skipped bool) bool {
+	return !skipped && c.busy != nil && c.session.Status() == pomoSession.PomoSessionWork
+}
+
+// Time past the end of state. Zero if not in overtime.
+func (c *PomoController) overtimeAmount(now time.Time) time.Duration {
+	if !c.overtime {
+		return 0
+	}
+	return now.Sub(*c.endOfState)
 }
 
 // start waiting for next timer event.
 func (c *PomoController) runTimer(now time.Time, status pomoSession.PomoSessionStatus) error {
+	c.goalCounted = false
 	statusDuration := c.durationFactory(status)
 	then := now.Add(statusDuration)
+	open := c.isOpen(status)
 
-	cb := func() {
-		if err := c.nextTimer(then); err != nil {
-			c.errorEvent(err)
-			// CONTROL THIS EVENT CHANGE...
-		}
+	busy, isBusy := c.busyDuring(status, now, then)
+	if isBusy && !busy.Start.After(now) {
+		c.busyPause(now, status, statusDuration, busy)
+		return nil
+	}
+	c.busy = nil
+	if isBusy && !open {
+		// SHORTENED TO END BEFORE BUSY TIME.
+		then = busy.Start
+		statusDuration = then.Sub(now)
+		c.busy = &busy
 	}
 
-	if err := c.timer.WaitCb(statusDuration, cb); err != nil {
-		c.errorEvent(err)
-		return err
+	// OPEN ENDED WORK HAS NO END TO WAIT FOR.
+	if !open {
+		if err := c.waitTimer(now, then); err != nil {
+			c.errorEvent(err)
+			return err
+		}
 	}
 
+	// WARNINGS DEPEND ON THE NEW STATUS.
 	c.session.SetNextStatus(status)
-	eos := now.Add(statusDuration)
-	c.endOfState = &eos
+	if !open {
+		c.waitWarning(now, then)
+	}
+	c.endOfState = &then
+	c.stateDuration = statusDuration
+	c.interruptions = PomoControllerInterruptions{}
+	c.snapshotEvent(now)
 	return nil
 }
 
 // Jump to the next status inmediately
-func (c *PomoController) Skip(now time.Time) error {
+func (c *PomoController) SkipCtx(ctx context.Context, now time.Time) error {
 	c.locker.Lock()
-	defer c.locker.Unlock()
+	defer c.unlock()
+
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
 
 	// It's ok to skip a paused timer but it will start the next timer right
 	// away
@@ -301,22 +819,71 @@ func (c *PomoController) Skip(now time.Time) error {
 		return ErrStoppedTimer
 	}
 
-	if err := c.timer.Cancel(); err != nil {
-		c.errorEvent(err)
-		return err
+	// STATE IS ALREADY OVER. NOTHING TO SKIP.
+	if c.overtime {
+		return c.advance(now, SessionLabel{})
+	}
+
+	// PAUSED CONTROLLERS HAVE NO TIMER RUNNING.
+	if c.pauseAt == nil {
+		if err := c.cancelTimer(); err != nil {
+			c.errorEvent(err)
+			return err
+		}
 	}
 
 	nextStatus := c.session.GetNextStatus()
-	c.endOfStateEvent(now)
-	// This is broken. if error rises it changes the state and keeps the
-	// existing work order...
+	// End of state event reports time spent up to the pause. Skip is how open
+	// ended work is done, it is not skipped.
+	c.stateEnded(now, !c.openEnded())
+	c.pauseAt = nil
 	return c.runTimer(now, nextStatus)
 }
 
+// Jump to the given status inmediately. A stopped controller starts on it.
+func (c *PomoController) SkipToCtx(
+	ctx context.Context,
+	now time.Time,
+	status pomoSession.PomoSessionStatus,
+) error {
+	c.locker.Lock()
+	defer c.unlock()
+
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
+
+	if c.endOfState == nil {
+		c.session.Reset()
+		if err := c.runTimer(now, status); err != nil {
+			return err
+		}
+		c.playEvent(now)
+		return nil
+	}
+
+	// PAUSED AND OVERTIME CONTROLLERS HAVE NO TIMER RUNNING.
+	if c.pauseAt == nil && !c.overtime {
+		if err := c.cancelTimer(); err != nil {
+			c.errorEvent(err)
+			return err
+		}
+	}
+
+	c.stateEnded(now, !c.openEnded())
+	c.pauseAt = nil
+	c.overtime = false
+	return c.runTimer(now, status)
+}
+
 // Reset controller to initial status
-func (c *PomoController) Stop(now time.Time) error {
+func (c *PomoController) StopCtx(ctx context.Context, now time.Time) error {
 	c.locker.Lock()
-	defer c.locker.Unlock()
+	defer c.unlock()
+
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
 
 	// THIS MUST DISMISS EVERY RUNNING GOROUTINE.
 	if c.endOfState == nil {
@@ -324,12 +891,168 @@ func (c *PomoController) Stop(now time.Time) error {
 		return ErrStoppedTimer
 	}
 
-	if err := c.timer.Cancel(); err != nil {
-		c.errorEvent(err)
-		return err
+	// PAUSED AND OVERTIME CONTROLLERS HAVE NO TIMER RUNNING.
+	if c.pauseAt == nil && !c.overtime {
+		if err := c.cancelTimer(); err != nil {
+			c.errorEvent(err)
+			return err
+		}
 	}
 
+	c.stopped(now)
+	return nil
+}
+
+// Report stop and clear the session. Timer must not be running.
+func (c *PomoController) stopped(now time.Time) {
 	c.stopEvent(now)
 	c.endOfState = nil
+	c.pauseAt = nil
+	c.busy = nil
+	c.overtime = false
+	c.goalCounted = false
+	c.label = SessionLabel{}
+	c.interruptions = PomoControllerInterruptions{}
+	c.snapshotEvent(now)
+}
+
+// Move the end of the current state by delta. Negative deltas shorten it, but
+// never past now (or the pause moment) so the state ends right away at most.
+func (c *PomoController) ExtendCtx(ctx context.Context, now time.Time, delta time.Duration) error {
+	c.locker.Lock()
+	defer c.unlock()
+
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
+
+	if c.endOfState == nil {
+		c.errorEvent(ErrStoppedTimer)
+		return ErrStoppedTimer
+	}
+
+	if c.openEnded() {
+		c.errorEvent(ErrOpenEndedTimer)
+		return ErrOpenEndedTimer
+	}
+
+	from := now
+	if c.pauseAt != nil {
+		from = *c.pauseAt
+	}
+
+	eos := c.endOfState.Add(delta)
+	if eos.Before(from) {
+		eos = from
+	}
+	applied := eos.Sub(*c.endOfState)
+
+	// PAUSED TIMER HAS NOTHING TO RESCHEDULE. OVERTIME IS LEFT ONLY IF THE
+	// NEW END OF STATE IS STILL TO COME.
+	switch {
+	case c.overtime:
+		if eos.After(now) {
+			if err := c.waitEndOfState(now, eos); err != nil {
+				c.errorEvent(err)
+				return err
+			}
+			c.overtime = false
+		}
+	case c.pauseAt == nil:
+		if err := c.cancelTimer(); err != nil {
+			c.errorEvent(err)
+			return err
+		}
+		if err := c.waitEndOfState(now, eos); err != nil {
+			c.errorEvent(err)
+			return err
+		}
+	}
+
+	c.endOfState = &eos
+	c.stateDuration += applied
+	c.extendEvent(now, applied)
+	c.snapshotEvent(now)
+	return nil
+}
+
+// Record an interruption of the current work interval. Paused intervals may be
+// interrupted too. Past the void threshold the work interval starts over with
+// its whole duration.
+func (c *PomoController) InterruptCtx(ctx context.Context, now time.Time, kind PomoInterruptionKind, note string) error {
+	c.locker.Lock()
+	defer c.unlock()
+
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
+
+	if c.endOfState == nil {
+		c.errorEvent(ErrStoppedTimer)
+		return ErrStoppedTimer
+	}
+
+	if c.overtime {
+		c.errorEvent(ErrOvertimeTimer)
+		return ErrOvertimeTimer
+	}
+
+	if c.session.Status() != pomoSession.PomoSessionWork {
+		c.errorEvent(ErrNotWorking)
+		return ErrNotWorking
+	}
+
+	interruptions := c.interruptions
+	if kind == PomoInterruptionExternal {
+		interruptions.External++
+	} else {
+		interruptions.Internal++
+	}
+
+	if c.voidThreshold <= 0 || interruptions.Total() <= c.voidThreshold {
+		c.interruptions = interruptions
+		c.interruptEvent(now, kind, note, interruptions, false)
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	// VOID: SAME STATE FROM SCRATCH. SESSION IS NOT MOVED. OPEN ENDED WORK
+	// COUNTS UP FROM ZERO AGAIN. PAUSED WORK STAYS PAUSED WITH ALL OF IT LEFT.
+	open := c.openEnded()
+	then := now.Add(c.stateDuration)
+	if c.pauseAt != nil {
+		pauseAt := now
+		c.pauseAt = &pauseAt
+	} else if !open {
+		if err := c.cancelTimer(); err != nil {
+			c.errorEvent(err)
+			return err
+		}
+		if err := c.waitEndOfState(now, then); err != nil {
+			c.errorEvent(err)
+			return err
+		}
+	}
+
+	c.endOfState = &then
+	c.interruptions = PomoControllerInterruptions{}
+	c.interruptEvent(now, kind, note, interruptions, true)
+	c.snapshotEvent(now)
+	return nil
+}
+
+// Attach a label to the current interval and the following ones. It may be
+// set on a stopped controller so the next play starts already labeled.
+func (c *PomoController) LabelCtx(ctx context.Context, now time.Time, label SessionLabel) error {
+	c.locker.Lock()
+	defer c.unlock()
+
+	if err := c.ctxErr(ctx); err != nil {
+		return err
+	}
+
+	c.label = label
+	c.labelEvent(now)
+	c.snapshotEvent(now)
 	return nil
 }
@@ -48,6 +48,26 @@ func mockControllerFactory(
 	return ControllerFactory(argOpts...)
 }
 
+// Controller timed by clock. See FakeClockControllerFactory.
+func fakeClockControllerFactory(
+	t *testing.T,
+	clock *pomoTimer.FakeClock,
+	options ...PomoControllerOption,
+) *PomoController {
+	controller, err := FakeClockControllerFactory(clock, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return controller
+}
+
+// Replaces the timer of the fixtures, e.g. by a mock one.
+func timerOpt(timer pomoTimer.PomoTimerIface) PomoControllerOption {
+	return PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+		return timer
+	})
+}
+
 // ============
 // ACTION TESTS
 // ============
@@ -504,6 +524,50 @@ func TestControllerSkipEvent(t *testing.T) {
 	}
 }
 
+func TestControllerSkipTo(t *testing.T) {
+	eventTime := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	timer := &checkTimer{}
+	session := sessionFactory()
+
+	var skipped []PomoControllerEventArgsNextState
+	controller, err := mockControllerFactory(
+		timer,
+		session,
+		PomoControllerOptionListener(PomoControllerListener{
+			NextState: func(event PomoControllerEventArgsNextState) {
+				skipped = append(skipped, event)
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// STOPPED CONTROLLER STARTS ON THE GIVEN STATE.
+	if err := controller.SkipTo(eventTime, pomoSession.PomoSessionLongBreak); err != nil {
+		t.Fatal(err)
+	}
+	if st := controller.Status().State; st != PomoControllerLongBreak {
+		t.Fatalf("Expected long break, got %s", st)
+	}
+
+	if err := controller.Pause(eventTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.SkipTo(eventTime, pomoSession.PomoSessionShortBreak); err != nil {
+		t.Fatal(err)
+	}
+	if st := controller.Status().State; st != PomoControllerShortBreak {
+		t.Fatalf("Expected short break, got %s", st)
+	}
+	if len(skipped) != 1 || !skipped[0].Skipped || skipped[0].CurrentState != PomoControllerLongBreak {
+		t.Fatalf("Expected skipped long break event, got %+v", skipped)
+	}
+	if !timer.isPending() || len(timer.violations) > 0 {
+		t.Fatalf("Expected one running timer, violations %v", timer.violations)
+	}
+}
+
 func TestControllerErrorEvent(t *testing.T) {
 
 	eventTime := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
@@ -543,3 +607,744 @@ func TestControllerErrorEvent(t *testing.T) {
 		t.Fatalf("Error sink not played")
 	}
 }
+
+func TestControllerLabel(t *testing.T) {
+
+	eventTime := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	label := SessionLabel{
+		Task:    "write report",
+		Project: "pomogo",
+		Tags:    []string{"docs"},
+	}
+
+	labelEventDone := false
+	labelSink := func(event PomoControllerEventArgsLabel) {
+		if event.Label.Task != label.Task {
+			t.Fatalf("Label event task is %q, expected %q", event.Label.Task, label.Task)
+		}
+		labelEventDone = true
+	}
+
+	playEventDone := false
+	playSink := func(event PomoControllerEventArgsPlay) {
+		if event.Label.Project != label.Project {
+			t.Fatalf("Play event project is %q, expected %q", event.Label.Project, label.Project)
+		}
+		playEventDone = true
+	}
+
+	stopEventDone := false
+	stopSink := func(event PomoControllerEventArgsStop) {
+		if len(event.Label.Tags) != 1 {
+			t.Fatalf("Stop event tags are %v, expected %v", event.Label.Tags, label.Tags)
+		}
+		stopEventDone = true
+	}
+
+	timer := &pomoTimer.MockCbTimer{}
+	session := sessionFactory()
+
+	controller, err := mockControllerFactory(
+		timer,
+		session,
+		PomoControllerOptionLabelSink(labelSink),
+		PomoControllerOptionPlaySink(playSink),
+		PomoControllerOptionStopSink(stopSink),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// LABEL ON STOPPED CONTROLLER IS KEPT FOR NEXT PLAY.
+	if err := controller.Label(eventTime, label); err != nil {
+		t.Fatal(err)
+	}
+
+	if !labelEventDone {
+		t.Fatalf("Label event must have runned")
+	}
+
+	if err := controller.Play(eventTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if !playEventDone {
+		t.Fatalf("Play event must have runned")
+	}
+
+	if st := controller.Status(); st.Label == nil || st.Label.Task != label.Task {
+		t.Fatalf("Status label is %v, expected %v", st.Label, label)
+	}
+
+	if err := controller.Stop(eventTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if !stopEventDone {
+		t.Fatalf("Stop event must have runned")
+	}
+
+	if st := controller.Status(); st.Label != nil {
+		t.Fatalf("Status label must be cleared on stop, got %v", st.Label)
+	}
+}
+
+// LABELLED PLAY ONLY RELABELS THE INTERVAL IT STARTS OR RESUMES.
+func TestControllerPlayLabel(t *testing.T) {
+
+	eventTime := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	first := SessionLabel{Task: "write report"}
+	second := SessionLabel{Task: "review"}
+
+	labels := []SessionLabel{}
+	nextStates := []PomoControllerEventArgsNextState{}
+	timer := &pomoTimer.MockCbTimer{}
+
+	controller, err := mockControllerFactory(
+		timer,
+		sessionFactory(),
+		PomoControllerOptionLabelSink(func(event PomoControllerEventArgsLabel) {
+			labels = append(labels, event.Label)
+		}),
+		PomoControllerOptionNextStateSink(func(event PomoControllerEventArgsNextState) {
+			nextStates = append(nextStates, event)
+		}),
+		PomoControllerOptionAdvancePolicy(PomoControllerAdvancePolicy{ManualAfterWork: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.PlayLabel(eventTime, first); err != nil {
+		t.Fatal(err)
+	}
+	if st := controller.Status(); st.Label == nil || st.Label.Task != first.Task {
+		t.Fatalf("Expected label %v, got %v", first, st.Label)
+	}
+
+	// FAILING PLAY KEEPS THE LABEL.
+	if err := controller.PlayLabel(eventTime, second); err != ErrRunningTimer {
+		t.Fatalf("Expected %v, got %v", ErrRunningTimer, err)
+	}
+	if st := controller.Status(); st.Label == nil || st.Label.Task != first.Task {
+		t.Fatalf("Expected label %v kept, got %v", first, st.Label)
+	}
+	if len(labels) != 1 {
+		t.Fatalf("Expected one label event, got %v", labels)
+	}
+
+	// OVERTIME WORK ENDS WITH ITS OWN LABEL. THE BREAK GETS THE NEW ONE.
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.PlayLabel(eventTime, second); err != nil {
+		t.Fatal(err)
+	}
+	if len(nextStates) != 1 || nextStates[0].Label.Task != first.Task {
+		t.Fatalf("Expected end of work with label %v, got %+v", first, nextStates)
+	}
+	st := controller.Status()
+	if st.State != PomoControllerShortBreak || st.Label == nil || st.Label.Task != second.Task {
+		t.Fatalf("Expected short break with label %v, got %+v", second, st)
+	}
+	if len(labels) != 2 || labels[1].Task != second.Task {
+		t.Fatalf("Expected label event of %v, got %v", second, labels)
+	}
+
+	// EMPTY LABEL KEEPS THE CURRENT ONE.
+	if err := controller.Pause(eventTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.PlayLabel(eventTime, SessionLabel{}); err != nil {
+		t.Fatal(err)
+	}
+	if st := controller.Status(); st.Label == nil || st.Label.Task != second.Task || len(labels) != 2 {
+		t.Fatalf("Expected label %v kept without event, got %v", second, st.Label)
+	}
+}
+
+func TestControllerExtend(t *testing.T) {
+
+	refNow := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+	session := sessionFactory()
+
+	extendEvents := []PomoControllerEventArgsExtend{}
+	extendSink := func(event PomoControllerEventArgsExtend) {
+		extendEvents = append(extendEvents, event)
+	}
+
+	controller, err := mockControllerFactory(
+		timer,
+		session,
+		PomoControllerOptionExtendSink(extendSink),
+		PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+			return snapshotDurationFactory
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Extend(refNow, time.Minute); err != ErrStoppedTimer {
+		t.Fatalf("Expected %v on stopped controller, got %v", ErrStoppedTimer, err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Extend(refNow, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if eos := controller.endOfState.Sub(refNow); eos != 30*time.Minute {
+		t.Fatalf("End of state is %s after start, expected 30m", eos)
+	}
+
+	// SHORTEN PAST NOW ENDS THE STATE NOW.
+	now := refNow.Add(10 * time.Minute)
+	if err := controller.Extend(now, -time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if !controller.endOfState.Equal(now) {
+		t.Fatalf("End of state is %s, expected %s", controller.endOfState, now)
+	}
+
+	if len(extendEvents) != 2 {
+		t.Fatalf("Expected 2 extend events, got %d", len(extendEvents))
+	}
+
+	if d := extendEvents[1].Delta; d != -20*time.Minute {
+		t.Fatalf("Applied delta is %s, expected -20m", d)
+	}
+
+	// TIMER MUST HAVE BEEN RESCHEDULED.
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := controller.Status().State; st != PomoControllerShortBreak {
+		t.Fatalf("Controller state is %s instead of short break", st)
+	}
+
+	// PAUSED EXTENSION ONLY MOVES END OF STATE.
+	if err := controller.Pause(now); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Extend(now, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if left := extendEvents[2].TimeLeft; left != 6*time.Minute {
+		t.Fatalf("Time left is %s, expected 6m", left)
+	}
+}
+
+func TestControllerOvertime(t *testing.T) {
+
+	refNow := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+	session := sessionFactory()
+
+	overtimeEventDone := false
+	overtimeSink := func(event PomoControllerEventArgsOvertime) {
+		if event.CurrentState != PomoControllerWork {
+			t.Fatalf("Overtime event state is %s, expected Work", event.CurrentState)
+		}
+		overtimeEventDone = true
+	}
+
+	nextStateEvents := []PomoControllerEventArgsNextState{}
+	nextStateSink := func(event PomoControllerEventArgsNextState) {
+		nextStateEvents = append(nextStateEvents, event)
+	}
+
+	controller, err := mockControllerFactory(
+		timer,
+		session,
+		PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+			return snapshotDurationFactory
+		}),
+		PomoControllerOptionAdvancePolicy(PomoControllerAdvancePolicy{
+			ManualAfterWork: true,
+		}),
+		PomoControllerOptionOvertimeSink(overtimeSink),
+		PomoControllerOptionNextStateSink(nextStateSink),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+
+	// END OF WORK WAITS FOR PLAY.
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !overtimeEventDone {
+		t.Fatalf("Overtime event must have runned")
+	}
+
+	st := controller.Status()
+	if st.State != PomoControllerOvertime || st.Overtime == nil {
+		t.Fatalf("Controller status is %+v, expected overtime", st)
+	}
+
+	if err := controller.Pause(refNow); err != ErrOvertimeTimer {
+		t.Fatalf("Expected %v on overtime pause, got %v", ErrOvertimeTimer, err)
+	}
+
+	confirmAt := refNow.Add(28 * time.Minute)
+	if err := controller.Play(confirmAt); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := controller.Status().State; st != PomoControllerShortBreak {
+		t.Fatalf("Controller state is %s instead of short break", st)
+	}
+
+	if len(nextStateEvents) != 1 || nextStateEvents[0].Overtime != 3*time.Minute {
+		t.Fatalf("Unexpected next state events %+v", nextStateEvents)
+	}
+
+	if spent := nextStateEvents[0].TimeSpent; spent != 28*time.Minute {
+		t.Fatalf("Time spent is %s, expected 28m", spent)
+	}
+
+	// END OF BREAK ADVANCES AUTOMATICALLY.
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := controller.Status().State; st != PomoControllerWork {
+		t.Fatalf("Controller state is %s instead of work", st)
+	}
+
+	// STOP ON OVERTIME HAS NO TIMER TO CANCEL.
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Stop(confirmAt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// OPEN ENDED WORK COUNTS UP IN WORK STATE UNTIL PLAY STARTS THE BREAK.
+func TestControllerOpenWork(t *testing.T) {
+
+	refNow := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	clock := pomoTimer.NewFakeClock(refNow)
+	durationCfg := pomoSession.SessionStateDurationConfig{
+		PomoSessionWork:       0,
+		PomoSessionShortBreak: 5 * time.Minute,
+		PomoSessionLongBreak:  15 * time.Minute,
+	}
+
+	nextStateEvents := []PomoControllerEventArgsNextState{}
+
+	controller, err := ControllerFactory(
+		PomoControllerSessionOpt(func() pomoSession.PomoSessionIface {
+			return sessionFactory()
+		}),
+		PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+			return &pomoTimer.PomoTimer{Clock: clock}
+		}),
+		PomoControllerDurationF(durationCfg.GetDurationFactory),
+		PomoControllerOptionClock(clock),
+		PomoControllerOptionOpenWork(true),
+		PomoControllerOptionOvertimeSink(func(event PomoControllerEventArgsOvertime) {
+			t.Fatalf("Unexpected overtime event %+v", event)
+		}),
+		PomoControllerOptionNextStateSink(func(event PomoControllerEventArgsNextState) {
+			nextStateEvents = append(nextStateEvents, event)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(10 * time.Minute)
+
+	st := controller.Status()
+	if st.State != PomoControllerWork || st.TimeLeft != nil || st.Elapsed == nil {
+		t.Fatalf("Controller status is %+v, expected open work", st)
+	}
+	if elapsed := time.Duration(*st.Elapsed); elapsed != 10*time.Minute {
+		t.Fatalf("Elapsed is %s, expected 10m", elapsed)
+	}
+
+	// PAUSED TIME IS NOT WORKED.
+	if err := controller.Pause(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Interrupt(clock.Now(), PomoInterruptionExternal, "phone"); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(5 * time.Minute)
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(20 * time.Minute)
+
+	if elapsed := time.Duration(*controller.Status().Elapsed); elapsed != 30*time.Minute {
+		t.Fatalf("Elapsed is %s, expected 30m", elapsed)
+	}
+
+	if err := controller.Extend(clock.Now(), time.Minute); err != ErrOpenEndedTimer {
+		t.Fatalf("Expected %v on open work extend, got %v", ErrOpenEndedTimer, err)
+	}
+
+	// PLAY STARTS THE BREAK. WORK IS DONE, NOT SKIPPED.
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nextStateEvents) != 1 {
+		t.Fatalf("Expected one end of state, got %+v", nextStateEvents)
+	}
+	if ev := nextStateEvents[0]; ev.TimeSpent != 30*time.Minute || ev.TimeLeft != 0 || ev.Skipped {
+		t.Fatalf("Unexpected end of work %+v", ev)
+	}
+
+	st = controller.Status()
+	if st.State != PomoControllerShortBreak || st.Elapsed != nil {
+		t.Fatalf("Controller status is %+v, expected short break", st)
+	}
+
+	// BREAK ENDS ON ITS OWN INTO OPEN WORK AGAIN.
+	clock.Advance(5 * time.Minute)
+	if st := controller.Status(); st.State != PomoControllerWork || st.TimeLeft != nil {
+		t.Fatalf("Controller status is %+v, expected open work", st)
+	}
+
+	// SKIP ENDS IT TOO.
+	clock.Advance(time.Minute)
+	if err := controller.Skip(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextStateEvents[len(nextStateEvents)-1]; ev.Skipped || ev.TimeSpent != time.Minute {
+		t.Fatalf("Unexpected end of work %+v", ev)
+	}
+}
+
+func TestControllerInterrupt(t *testing.T) {
+
+	refNow := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+	session := sessionFactory()
+
+	interruptEvents := []PomoControllerEventArgsInterrupt{}
+	interruptSink := func(event PomoControllerEventArgsInterrupt) {
+		interruptEvents = append(interruptEvents, event)
+	}
+
+	controller, err := mockControllerFactory(
+		timer,
+		session,
+		PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+			return snapshotDurationFactory
+		}),
+		PomoControllerOptionVoidThreshold(2),
+		PomoControllerOptionInterruptSink(interruptSink),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Interrupt(refNow, PomoInterruptionInternal, ""); err != ErrStoppedTimer {
+		t.Fatalf("Expected %v on stopped controller, got %v", ErrStoppedTimer, err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Interrupt(refNow, PomoInterruptionInternal, "email"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Interrupt(refNow, PomoInterruptionExternal, "phone call"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := PomoControllerInterruptions{Internal: 1, External: 1}
+	if st := controller.Status(); st.Interruptions != expected {
+		t.Fatalf("Interruptions are %+v, expected %+v", st.Interruptions, expected)
+	}
+
+	// THIRD ONE EXCEEDS THRESHOLD. WORK STARTS OVER.
+	voidAt := refNow.Add(10 * time.Minute)
+	if err := controller.Interrupt(voidAt, PomoInterruptionExternal, "meeting"); err != nil {
+		t.Fatal(err)
+	}
+
+	last := interruptEvents[len(interruptEvents)-1]
+	if !last.Voided || last.Interruptions.Total() != 3 || last.Note != "meeting" {
+		t.Fatalf("Unexpected void event %+v", last)
+	}
+
+	if st := controller.Status(); st.Interruptions.Total() != 0 || st.State != PomoControllerWork {
+		t.Fatalf("Unexpected status after void %+v", st)
+	}
+
+	if eos := controller.endOfState.Sub(voidAt); eos != 25*time.Minute {
+		t.Fatalf("End of state is %s after void, expected 25m", eos)
+	}
+
+	// BREAKS CANNOT BE INTERRUPTED.
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Interrupt(voidAt, PomoInterruptionInternal, ""); err != ErrNotWorking {
+		t.Fatalf("Expected %v on break, got %v", ErrNotWorking, err)
+	}
+}
+
+// VOIDING PAUSED WORK DOES NOT RESUME IT.
+func TestControllerInterruptPaused(t *testing.T) {
+
+	refNow := time.Date(2024, 12, 06, 0, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+
+	controller, err := mockControllerFactory(
+		timer,
+		sessionFactory(),
+		PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+			return snapshotDurationFactory
+		}),
+		PomoControllerOptionVoidThreshold(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Interrupt(refNow, PomoInterruptionInternal, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Pause(refNow.Add(5 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	voidAt := refNow.Add(10 * time.Minute)
+	if err := controller.Interrupt(voidAt, PomoInterruptionExternal, "visit"); err != nil {
+		t.Fatal(err)
+	}
+
+	st := controller.Status()
+	if st.State != PomoControllerPause || st.Interruptions.Total() != 0 {
+		t.Fatalf("Unexpected status after void %+v", st)
+	}
+	if left := controller.endOfState.Sub(*controller.pauseAt); left != 25*time.Minute {
+		t.Fatalf("Time left is %s after void, expected 25m", left)
+	}
+
+	// NO TIMER RUNNING WHILE PAUSED.
+	if err := timer.ForceDone(); err != pomoTimer.ErrTimerNotWaited {
+		t.Fatalf("Expected %v, got %v", pomoTimer.ErrTimerNotWaited, err)
+	}
+
+	playAt := refNow.Add(20 * time.Minute)
+	if err := controller.Play(playAt); err != nil {
+		t.Fatal(err)
+	}
+	if eos := controller.endOfState.Sub(playAt); eos != 25*time.Minute {
+		t.Fatalf("End of state is %s after play, expected 25m", eos)
+	}
+}
+
+// ================
+// SIMULATION TESTS
+// ================
+
+// A WHOLE DAY ON A FAKE CLOCK. 25/5/15 MINUTES WITH LONG BREAK EVERY 4 WORK
+// SESSIONS MAKES 130 MINUTES CYCLES.
+func TestControllerFakeClockDay(t *testing.T) {
+	refNow := time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC)
+	clock := pomoTimer.NewFakeClock(refNow)
+	timer := &pomoTimer.PomoTimer{Clock: clock}
+
+	durationCfg := pomoSession.SessionStateDurationConfig{
+		PomoSessionWork:       25 * time.Minute,
+		PomoSessionShortBreak: 5 * time.Minute,
+		PomoSessionLongBreak:  15 * time.Minute,
+	}
+
+	endOfStates := 0
+	controller, err := ControllerFactory(
+		PomoControllerSessionOpt(func() pomoSession.PomoSessionIface {
+			return sessionFactory()
+		}),
+		PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+			return timer
+		}),
+		PomoControllerDurationF(durationCfg.GetDurationFactory),
+		PomoControllerOptionClock(clock),
+		PomoControllerOptionNextStateSink(
+			func(event PomoControllerEventArgsNextState) {
+				endOfStates++
+			},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(24 * time.Hour)
+
+	// 11 CYCLES OF 8 STATES AND 10 MINUTES INTO THE NEXT WORK SESSION.
+	if endOfStates != 88 {
+		t.Fatalf("Expected 88 end of states, got %d", endOfStates)
+	}
+
+	status := controller.Status()
+	if status.State != PomoControllerWork {
+		t.Fatalf("Expected work state, got %s", status.State)
+	}
+	if status.TimeLeft == nil || time.Duration(*status.TimeLeft) != 15*time.Minute {
+		t.Fatalf("Expected 15 minutes left, got %v", status.TimeLeft)
+	}
+}
+
+// -----------
+// CLOCK JUMPS
+// -----------
+
+func wallControllerFactory(
+	t *testing.T,
+	policy PomoControllerJumpPolicy,
+	endOfStates *int,
+) (*PomoController, *pomoTimer.FakeClock) {
+	clock := pomoTimer.NewFakeClock(time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC))
+	controller := fakeClockControllerFactory(
+		t,
+		clock,
+		timerOpt(&pomoTimer.WallTimer{Clock: clock}),
+		PomoControllerOptionJumpPolicy(policy),
+		PomoControllerOptionNextStateSink(
+			func(event PomoControllerEventArgsNextState) {
+				*endOfStates++
+			},
+		),
+	)
+	return controller, clock
+}
+
+// SUSPENDED 10 MINUTES INTO WORK FOR AN HOUR.
+func suspendedController(
+	t *testing.T,
+	policy PomoControllerJumpPolicy,
+) (*PomoController, *pomoTimer.FakeClock, int) {
+	endOfStates := 0
+	controller, clock := wallControllerFactory(t, policy, &endOfStates)
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(10 * time.Minute)
+	clock.Jump(time.Hour)
+	clock.Advance(time.Second)
+	return controller, clock, endOfStates
+}
+
+func TestControllerJumpFire(t *testing.T) {
+	controller, _, endOfStates := suspendedController(t, PomoControllerJumpFire)
+
+	if endOfStates != 1 {
+		t.Fatalf("Expected one end of state, got %d", endOfStates)
+	}
+	status := controller.Status()
+	if status.State != PomoControllerShortBreak {
+		t.Fatalf("Expected short break, got %s", status.State)
+	}
+	if time.Duration(*status.TimeLeft) != 5*time.Minute {
+		t.Fatalf("Expected full break left, got %s", time.Duration(*status.TimeLeft))
+	}
+}
+
+func TestControllerJumpCatchUp(t *testing.T) {
+	controller, _, endOfStates := suspendedController(t, PomoControllerJumpCatchUp)
+
+	// WORK, BREAK, WORK AND BREAK ARE OVER 70 MINUTES IN.
+	if endOfStates != 4 {
+		t.Fatalf("Expected 4 end of states, got %d", endOfStates)
+	}
+	status := controller.Status()
+	if status.State != PomoControllerWork {
+		t.Fatalf("Expected work, got %s", status.State)
+	}
+	if time.Duration(*status.TimeLeft) != 15*time.Minute-time.Second {
+		t.Fatalf("Expected 14m59s left, got %s", time.Duration(*status.TimeLeft))
+	}
+}
+
+func TestControllerJumpPause(t *testing.T) {
+	controller, clock, endOfStates := suspendedController(t, PomoControllerJumpPause)
+
+	if endOfStates != 0 {
+		t.Fatalf("Expected no end of state, got %d", endOfStates)
+	}
+	status := controller.Status()
+	if status.State != PomoControllerPause {
+		t.Fatalf("Expected pause, got %s", status.State)
+	}
+	suspendAt := time.Date(2024, 12, 04, 0, 10, 0, 0, time.UTC)
+	if status.PausedAt == nil || !status.PausedAt.Equal(suspendAt) {
+		t.Fatalf("Expected pause when suspended, got %v", status.PausedAt)
+	}
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(15 * time.Minute)
+	if controller.Status().State != PomoControllerShortBreak {
+		t.Fatalf("Expected short break after resume")
+	}
+}
+
+// A SHORT SUSPEND KEEPS THE ORIGINAL WALL CLOCK END OF STATE.
+func TestControllerJumpWithinState(t *testing.T) {
+	endOfStates := 0
+	controller, clock := wallControllerFactory(t, PomoControllerJumpFire, &endOfStates)
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(5 * time.Minute)
+	clock.Jump(10 * time.Minute)
+	clock.Advance(time.Second)
+
+	status := controller.Status()
+	if status.State != PomoControllerWork {
+		t.Fatalf("Expected work, got %s", status.State)
+	}
+	if time.Duration(*status.TimeLeft) != 10*time.Minute-time.Second {
+		t.Fatalf("Expected 9m59s left, got %s", time.Duration(*status.TimeLeft))
+	}
+
+	clock.Advance(10*time.Minute - time.Second)
+	if endOfStates != 1 {
+		t.Fatalf("Expected work to end on time, got %d end of states", endOfStates)
+	}
+}
@@ -2,7 +2,9 @@ package controller
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/FernandoAFS/pomogo/session"
+	"strings"
 )
 
 // ===================
@@ -24,6 +26,8 @@ func (s PomoControllerState) String() string {
 		return "Paused"
 	case PomoControllerStopped:
 		return "Stopped"
+	case PomoControllerOvertime:
+		return "Overtime"
 	}
 
 	panic("Impossible PomoControllerState value")
@@ -45,7 +49,7 @@ func SessionToControllerState(s session.PomoSessionStatus) PomoControllerState {
 
 func (s *PomoControllerState) UnmarshalJSON(b []byte) error {
 
-	sr := string(b)
+	sr := strings.Trim(string(b), `"`)
 	switch sr {
 	case "Work":
 		*s = PomoControllerWork
@@ -57,6 +61,8 @@ func (s *PomoControllerState) UnmarshalJSON(b []byte) error {
 		*s = PomoControllerPause
 	case "Stopped":
 		*s = PomoControllerStopped
+	case "Overtime":
+		*s = PomoControllerOvertime
 	default:
 		return &json.MarshalerError{}
 	}
@@ -78,6 +84,8 @@ func (s *PomoControllerState) MarshalJSON() ([]byte, error) {
 		sr = `"Paused"`
 	case PomoControllerStopped:
 		sr = `"Stopped"`
+	case PomoControllerOvertime:
+		sr = `"Overtime"`
 	default:
 		return nil, &json.MarshalerError{}
 	}
@@ -90,6 +98,7 @@ const (
 	PomoControllerLongBreak
 	PomoControllerPause
 	PomoControllerStopped
+	PomoControllerOvertime
 )
 
 // =======================
@@ -103,6 +112,13 @@ const (
 	PomoControllerEventTypeStop
 	PomoControllerEventTypePause
 	PomoControllerEventTypeNextState
+	PomoControllerEventTypeLabel
+	PomoControllerEventTypeExtend
+	PomoControllerEventTypeOvertime
+	PomoControllerEventTypeInterrupt
+	PomoControllerEventTypeGoal
+	PomoControllerEventTypeWarning
+	PomoControllerEventTypeError
 )
 
 func (s PomoControllerEventType) String() string {
@@ -116,7 +132,132 @@ func (s PomoControllerEventType) String() string {
 		return "Pause"
 	case PomoControllerEventTypeNextState:
 		return "NextState"
+	case PomoControllerEventTypeLabel:
+		return "Label"
+	case PomoControllerEventTypeExtend:
+		return "Extend"
+	case PomoControllerEventTypeOvertime:
+		return "Overtime"
+	case PomoControllerEventTypeInterrupt:
+		return "Interrupt"
+	case PomoControllerEventTypeGoal:
+		return "Goal"
+	case PomoControllerEventTypeWarning:
+		return "Warning"
+	case PomoControllerEventTypeError:
+		return "Error"
 	}
 
 	panic("Impossible PomoControllerEventType value")
 }
+
+// ====================
+// PomoInterruptionKind
+// ====================
+
+// Internal interruptions come from oneself, external ones from others.
+type PomoInterruptionKind int
+
+const (
+	PomoInterruptionInternal PomoInterruptionKind = iota
+	PomoInterruptionExternal
+)
+
+func (k PomoInterruptionKind) String() string {
+
+	switch k {
+	case PomoInterruptionInternal:
+		return "Internal"
+	case PomoInterruptionExternal:
+		return "External"
+	}
+
+	panic("Impossible PomoInterruptionKind value")
+}
+
+func ParseInterruptionKind(s string) (PomoInterruptionKind, error) {
+	switch strings.ToLower(s) {
+	case "internal":
+		return PomoInterruptionInternal, nil
+	case "external":
+		return PomoInterruptionExternal, nil
+	}
+	return 0, fmt.Errorf("invalid interruption kind: %s", s)
+}
+
+// ===========================
+// PomoControllerRestorePolicy
+// ===========================
+
+// What to do with a running snapshot on restore.
+type PomoControllerRestorePolicy int
+
+const (
+	PomoControllerRestoreCatchUp PomoControllerRestorePolicy = iota
+	PomoControllerRestorePause
+)
+
+func (p PomoControllerRestorePolicy) String() string {
+
+	switch p {
+	case PomoControllerRestoreCatchUp:
+		return "catchup"
+	case PomoControllerRestorePause:
+		return "pause"
+	}
+
+	panic("Impossible PomoControllerRestorePolicy value")
+}
+
+func ParseRestorePolicy(s string) (PomoControllerRestorePolicy, error) {
+	switch strings.ToLower(s) {
+	case "catchup":
+		return PomoControllerRestoreCatchUp, nil
+	case "pause":
+		return PomoControllerRestorePause, nil
+	}
+	return 0, fmt.Errorf("invalid restore policy: %s", s)
+}
+
+// ========================
+// PomoControllerJumpPolicy
+// ========================
+
+// What to do when the timer notices a clock jump, like a system suspend.
+type PomoControllerJumpPolicy int
+
+const (
+	// End the current state right away if it's over. Following states start
+	// on wake up.
+	PomoControllerJumpFire PomoControllerJumpPolicy = iota
+	// Move through every state that ended while asleep.
+	PomoControllerJumpCatchUp
+	// Pause at the moment the clock jumped.
+	PomoControllerJumpPause
+)
+
+func (p PomoControllerJumpPolicy) String() string {
+
+	switch p {
+	case PomoControllerJumpFire:
+		return "fire"
+	case PomoControllerJumpCatchUp:
+		return "catchup"
+	case PomoControllerJumpPause:
+		return "pause"
+	}
+
+	panic("Impossible PomoControllerJumpPolicy value")
+}
+
+func ParseJumpPolicy(s string) (PomoControllerJumpPolicy, error) {
+	switch strings.ToLower(s) {
+	case "fire":
+		return PomoControllerJumpFire, nil
+	case "catchup":
+		return PomoControllerJumpCatchUp, nil
+	case "pause":
+		return PomoControllerJumpPause, nil
+	}
+	return 0, fmt.Errorf("invalid jump policy: %s", s)
+}
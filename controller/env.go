@@ -0,0 +1,216 @@
+// Environment variables of exec hooks.
+
+package controller
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Grows on breaking changes of the hook environment only. New variables may
+// be added without notice.
+const HookEnvVersion = 1
+
+// Contract variables. Common ones are always set, empty if they don't apply to
+// the event. The rest are only set on their events.
+const (
+	hookEnvVersionName  = "POMOGO_ENV_VERSION"
+	hookEnvEvent        = "POMOGO_EVENT"
+	hookEnvTimestamp    = "POMOGO_TIMESTAMP"
+	hookEnvState        = "POMOGO_STATE"
+	hookEnvNextState    = "POMOGO_NEXT_STATE"
+	hookEnvTimeSpent    = "POMOGO_TIME_SPENT_SECONDS"
+	hookEnvTimeLeft     = "POMOGO_TIME_LEFT_SECONDS"
+	hookEnvWorked       = "POMOGO_WORKED_SESSIONS"
+	hookEnvTask         = "POMOGO_TASK"
+	hookEnvProject      = "POMOGO_PROJECT"
+	hookEnvTags         = "POMOGO_TAGS"
+	hookEnvDuration     = "POMOGO_STATE_DURATION_SECONDS"
+	hookEnvOvertime     = "POMOGO_OVERTIME_SECONDS"
+	hookEnvSkipped      = "POMOGO_SKIPPED"
+	hookEnvCaughtUp     = "POMOGO_CAUGHT_UP"
+	hookEnvShortened    = "POMOGO_SHORTENED"
+	hookEnvDelta        = "POMOGO_DELTA_SECONDS"
+	hookEnvPauseReason  = "POMOGO_PAUSE_REASON"
+	hookEnvInterruption = "POMOGO_INTERRUPTION_KIND"
+	hookEnvNote         = "POMOGO_INTERRUPTION_NOTE"
+	hookEnvInterrupts   = "POMOGO_INTERRUPTIONS"
+	hookEnvVoided       = "POMOGO_VOIDED"
+	hookEnvGoalDay      = "POMOGO_GOAL_DAY"
+	hookEnvGoalTarget   = "POMOGO_GOAL_TARGET"
+	hookEnvGoalDone     = "POMOGO_GOAL_DONE"
+	hookEnvError        = "POMOGO_ERROR"
+)
+
+// DEPRECATED ALIASES. KEPT FOR ONE RELEASE WITH THEIR OLD FORMAT.
+const (
+	hookEnvLegacyEvent  = "POMO_EVENT"
+	hookEnvLegacyStatus = "POMO_STATUS"
+	hookEnvLegacyAt     = "POMO_AT"
+	hookEnvOldStatus    = "POMOGO_STATUS"
+	hookEnvOldAt        = "POMOGO_AT"
+)
+
+// Environment of exec hooks for payload, as NAME=value pairs. Contract
+// variables first, deprecated aliases after.
+func HookEnv(payload HookPayload) []string {
+	env := map[string]string{
+		hookEnvVersionName: strconv.Itoa(HookEnvVersion),
+		hookEnvEvent:       payload.Event,
+		hookEnvTimestamp:   payload.At.Format(time.RFC3339),
+		hookEnvState:       "",
+		hookEnvNextState:   "",
+		hookEnvTimeSpent:   "",
+		hookEnvTimeLeft:    "",
+		hookEnvWorked:      "",
+		hookEnvTask:        "",
+		hookEnvProject:     "",
+		hookEnvTags:        "",
+	}
+
+	// FORMER POMOGO_STATUS. CURRENT STATE UNLESS SAID OTHERWISE.
+	legacyStatus := ""
+
+	setLabel := func(label SessionLabel) {
+		env[hookEnvTask] = label.Task
+		env[hookEnvProject] = label.Project
+		env[hookEnvTags] = strings.Join(label.Tags, ",")
+	}
+
+	switch data := payload.Data.(type) {
+	case HookDataPlay:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvNextState] = data.NextState
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		env[hookEnvDuration] = envSeconds(data.CurrentStateDurationSeconds)
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataStop:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvTimeSpent] = envSeconds(data.TimeSpentSeconds)
+		env[hookEnvTimeLeft] = envSeconds(data.TimeLeftSeconds)
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		env[hookEnvOvertime] = envSeconds(data.OvertimeSeconds)
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataPause:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvTimeSpent] = envSeconds(data.TimeSpentSeconds)
+		env[hookEnvTimeLeft] = envSeconds(data.TimeLeftSeconds)
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		env[hookEnvPauseReason] = data.Reason
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataEndOfState:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvNextState] = data.NextState
+		env[hookEnvTimeSpent] = envSeconds(data.TimeSpentSeconds)
+		env[hookEnvTimeLeft] = envSeconds(data.TimeLeftSeconds)
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		env[hookEnvOvertime] = envSeconds(data.OvertimeSeconds)
+		env[hookEnvSkipped] = strconv.FormatBool(data.Skipped)
+		env[hookEnvCaughtUp] = strconv.FormatBool(data.CaughtUp)
+		env[hookEnvShortened] = strconv.FormatBool(data.Shortened)
+		setLabel(data.Label)
+		legacyStatus = data.NextState
+	case HookDataLabel:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataExtend:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvTimeLeft] = envSeconds(data.TimeLeftSeconds)
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		env[hookEnvDelta] = envSeconds(data.DeltaSeconds)
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataOvertime:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvNextState] = data.NextState
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataInterrupt:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		env[hookEnvInterruption] = data.Kind
+		env[hookEnvNote] = data.Note
+		env[hookEnvInterrupts] = strconv.Itoa(data.Interruptions.Total())
+		env[hookEnvVoided] = strconv.FormatBool(data.Voided)
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataGoalReached:
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		env[hookEnvGoalDay] = data.Progress.Day
+		env[hookEnvGoalTarget] = strconv.Itoa(data.Progress.Target)
+		env[hookEnvGoalDone] = strconv.Itoa(data.Progress.Done)
+		setLabel(data.Label)
+		legacyStatus = data.Progress.Day
+	case HookDataWarning:
+		env[hookEnvState] = data.CurrentState
+		env[hookEnvNextState] = data.NextState
+		env[hookEnvTimeLeft] = envSeconds(data.TimeLeftSeconds)
+		env[hookEnvWorked] = strconv.Itoa(data.WorkedSessions)
+		setLabel(data.Label)
+		legacyStatus = data.CurrentState
+	case HookDataError:
+		env[hookEnvError] = data.Message
+		legacyStatus = data.Message
+	}
+
+	env[hookEnvLegacyEvent] = payload.Event
+	env[hookEnvLegacyStatus] = legacyStatus
+	env[hookEnvLegacyAt] = payload.At.Format(time.RFC3339)
+	env[hookEnvOldStatus] = legacyStatus
+	env[hookEnvOldAt] = payload.At.String()
+
+	pairs := make([]string, 0, len(env))
+	for _, name := range hookEnvOrder {
+		if value, ok := env[name]; ok {
+			pairs = append(pairs, name+"="+value)
+		}
+	}
+	return pairs
+}
+
+// Stable order of the variables so the environment is reproducible.
+var hookEnvOrder = []string{
+	hookEnvVersionName,
+	hookEnvEvent,
+	hookEnvTimestamp,
+	hookEnvState,
+	hookEnvNextState,
+	hookEnvTimeSpent,
+	hookEnvTimeLeft,
+	hookEnvWorked,
+	hookEnvTask,
+	hookEnvProject,
+	hookEnvTags,
+	hookEnvDuration,
+	hookEnvOvertime,
+	hookEnvSkipped,
+	hookEnvCaughtUp,
+	hookEnvShortened,
+	hookEnvDelta,
+	hookEnvPauseReason,
+	hookEnvInterruption,
+	hookEnvNote,
+	hookEnvInterrupts,
+	hookEnvVoided,
+	hookEnvGoalDay,
+	hookEnvGoalTarget,
+	hookEnvGoalDone,
+	hookEnvError,
+	hookEnvLegacyEvent,
+	hookEnvLegacyStatus,
+	hookEnvLegacyAt,
+	hookEnvOldStatus,
+	hookEnvOldAt,
+}
+
+// Same format as json payload seconds: no exponent, no trailing zeros.
+func envSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+func envMap(pairs []string) map[string]string {
+	env := map[string]string{}
+	for _, pair := range pairs {
+		name, value, _ := strings.Cut(pair, "=")
+		env[name] = value
+	}
+	return env
+}
+
+func checkEnv(t *testing.T, env map[string]string, expected map[string]string) {
+	t.Helper()
+	for name, value := range expected {
+		got, ok := env[name]
+		if !ok {
+			t.Fatalf("Expected %s to be set", name)
+		}
+		if got != value {
+			t.Fatalf("Expected %s=%q, got %q", name, value, got)
+		}
+	}
+}
+
+// =====
+// TESTS
+// =====
+
+// THE ENVIRONMENT IS A CONTRACT WITH USER SCRIPTS. CHANGES HERE MUST BE
+// ADDITIVE UNLESS HookEnvVersion GROWS.
+func TestHookEnvContract(t *testing.T) {
+	at := time.Date(2024, 12, 06, 9, 25, 0, 0, time.UTC)
+
+	env := envMap(HookEnv(endOfStatePayload(PomoControllerEventArgsNextState{
+		At:             at,
+		CurrentState:   PomoControllerWork,
+		NextState:      PomoControllerShortBreak,
+		TimeSpent:      1490 * time.Second,
+		TimeLeft:       10 * time.Second,
+		Skipped:        true,
+		WorkedSessions: 3,
+		Label:          SessionLabel{Task: "report", Project: "acme", Tags: []string{"a", "b"}},
+	})))
+
+	checkEnv(t, env, map[string]string{
+		"POMOGO_ENV_VERSION":        "1",
+		"POMOGO_EVENT":              "EndOfState",
+		"POMOGO_TIMESTAMP":          "2024-12-06T09:25:00Z",
+		"POMOGO_STATE":              "Work",
+		"POMOGO_NEXT_STATE":         "ShortBreak",
+		"POMOGO_TIME_SPENT_SECONDS": "1490",
+		"POMOGO_TIME_LEFT_SECONDS":  "10",
+		"POMOGO_WORKED_SESSIONS":    "3",
+		"POMOGO_TASK":               "report",
+		"POMOGO_PROJECT":            "acme",
+		"POMOGO_TAGS":               "a,b",
+		"POMOGO_OVERTIME_SECONDS":   "0",
+		"POMOGO_SKIPPED":            "true",
+		"POMOGO_CAUGHT_UP":          "false",
+		"POMOGO_SHORTENED":          "false",
+	})
+}
+
+// COMMON VARIABLES ARE ALWAYS SET, EMPTY WHEN THEY DON'T APPLY.
+func TestHookEnvCommon(t *testing.T) {
+	at := time.Date(2024, 12, 06, 9, 25, 0, 0, time.UTC)
+
+	env := envMap(HookEnv(errorPayload(at, ErrStoppedTimer)))
+
+	checkEnv(t, env, map[string]string{
+		"POMOGO_EVENT":              "Error",
+		"POMOGO_ERROR":              ErrStoppedTimer.Error(),
+		"POMOGO_STATE":              "",
+		"POMOGO_NEXT_STATE":         "",
+		"POMOGO_TIME_SPENT_SECONDS": "",
+		"POMOGO_TIME_LEFT_SECONDS":  "",
+		"POMOGO_WORKED_SESSIONS":    "",
+		"POMOGO_TASK":               "",
+		"POMOGO_PROJECT":            "",
+		"POMOGO_TAGS":               "",
+	})
+
+	if _, ok := env["POMOGO_SKIPPED"]; ok {
+		t.Fatalf("Expected event specific variables to be left out")
+	}
+}
+
+// DEPRECATED NAMES KEEP THEIR OLD MEANING AND FORMAT.
+func TestHookEnvDeprecated(t *testing.T) {
+	at := time.Date(2024, 12, 06, 9, 25, 0, 0, time.UTC)
+
+	endOfState := envMap(HookEnv(endOfStatePayload(PomoControllerEventArgsNextState{
+		At:           at,
+		CurrentState: PomoControllerWork,
+		NextState:    PomoControllerShortBreak,
+	})))
+	checkEnv(t, endOfState, map[string]string{
+		"POMO_EVENT":    "EndOfState",
+		"POMO_STATUS":   "ShortBreak",
+		"POMO_AT":       "2024-12-06T09:25:00Z",
+		"POMOGO_STATUS": "ShortBreak",
+		"POMOGO_AT":     at.String(),
+	})
+
+	warning := envMap(HookEnv(warningPayload(PomoControllerEventArgsWarning{
+		At:           at,
+		CurrentState: PomoControllerWork,
+		NextState:    PomoControllerLongBreak,
+		TimeLeft:     2 * time.Minute,
+	})))
+	checkEnv(t, warning, map[string]string{
+		"POMOGO_STATUS":            "Work",
+		"POMOGO_NEXT_STATE":        "LongBreak",
+		"POMOGO_TIME_LEFT_SECONDS": "120",
+	})
+	for _, name := range []string{"POMOGO_NEXT_STATUS", "POMOGO_TIME_LEFT"} {
+		if _, ok := warning[name]; ok {
+			t.Fatalf("Unexpected %s on warning", name)
+		}
+	}
+
+	goal := envMap(HookEnv(goalPayload(PomoControllerEventArgsGoal{
+		At:       at,
+		Progress: PomoControllerGoalProgress{Day: "2024-12-06", Target: 8, Done: 8},
+	})))
+	checkEnv(t, goal, map[string]string{
+		"POMOGO_STATUS":      "2024-12-06",
+		"POMOGO_GOAL_DAY":    "2024-12-06",
+		"POMOGO_GOAL_TARGET": "8",
+		"POMOGO_GOAL_DONE":   "8",
+	})
+
+	failure := envMap(HookEnv(errorPayload(at, ErrStoppedTimer)))
+	checkEnv(t, failure, map[string]string{
+		"POMO_STATUS":   ErrStoppedTimer.Error(),
+		"POMOGO_STATUS": ErrStoppedTimer.Error(),
+	})
+}
+
+// THE HOOK PROCESS GETS THE CONTRACT ON TOP OF THE SERVER ENVIRONMENT.
+func TestHookEnvExec(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "env")
+	script := filepath.Join(dir, "hook.sh")
+	content := "#!/bin/sh\necho \"$POMOGO_EVENT $POMOGO_STATE $POMOGO_PAUSE_REASON $HOME\" > " + out + "\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	PauseExecHook(script)(PomoControllerEventArgsPause{
+		At:           time.Now(),
+		CurrentState: PomoControllerWork,
+		Reason:       "busy: Planning",
+	})
+	execHookRunner.Wait()
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Pause Work busy: Planning " + os.Getenv("HOME") + "\n"
+	if string(b) != expected {
+		t.Fatalf("Expected %q, got %q", expected, b)
+	}
+}
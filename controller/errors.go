@@ -5,5 +5,18 @@ import "errors"
 var ErrStoppedTimer = errors.New("cannot execute action on stopped timer")
 var ErrPausedTimer = errors.New("cannot execute action on paused timer")
 var ErrRunningTimer = errors.New("cannot execute action on running timer")
+var ErrOvertimeTimer = errors.New("cannot execute action on overtime")
+var ErrOpenEndedTimer = errors.New("cannot execute action on open ended state")
+var ErrNotWorking = errors.New("cannot execute action out of work state")
 var ErrNoControllerError = errors.New("must create a controller first")
 var ErrExistintgControllerError = errors.New("must remove existing controller")
+var ErrSnapshotUnsupported = errors.New("session does not support snapshots")
+var ErrRestoreExpired = errors.New("snapshot too old to catch up, session stopped")
+var ErrJumpExpired = errors.New("clock jump too long to catch up, session stopped")
+var ErrInvalidTickInterval = errors.New("tick interval too short")
+var ErrInvalidWarning = errors.New("invalid warning")
+var ErrCalendarBusy = errors.New("cannot start work on busy time")
+var ErrHookFailed = errors.New("hook failed")
+var ErrHookTimeout = errors.New("hook timed out")
+var ErrInvalidHook = errors.New("invalid hook, expected event=command")
+var ErrInvalidHookEvent = errors.New("invalid hook event")
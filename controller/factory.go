@@ -3,6 +3,7 @@
 package controller
 
 import (
+	"context"
 	pomoSession "github.com/FernandoAFS/pomogo/session"
 	pomoTimer "github.com/FernandoAFS/pomogo/timer"
 )
@@ -72,85 +73,278 @@ func PomoControllerDurationF(
 	}
 }
 
-// Sets error sinks
-func PomoControllerOptionErrorSink(errorSink func(err error)) PomoControllerOption {
+// Root context of the controller. Once done every timer is cancelled and
+// actions fail with its error.
+func PomoControllerOptionContext(ctx context.Context) PomoControllerOption {
 	return func(c *PomoController) (PomoControllerOption, error) {
-		prev := c.errorSink
-		c.errorSink = errorSink
-		return PomoControllerOptionErrorSink(prev), nil
+		prev := c.ctx
+		c.ctx = ctx
+		stop := context.AfterFunc(ctx, c.shutdown)
+		return func(c *PomoController) (PomoControllerOption, error) {
+			stop()
+			c.ctx = prev
+			return PomoControllerOptionContext(ctx), nil
+		}, nil
 	}
 }
 
-// Sets play sinks
+// Sets source of busy time blocking work. Nil disables it.
+func PomoControllerOptionCalendar(calendar PomoControllerCalendarIface) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.calendar
+		c.calendar = calendar
+		return PomoControllerOptionCalendar(prev), nil
+	}
+}
+
+// Sets who is told the time worked before every break, like flowtime
+// durations. Nil disables it.
+func PomoControllerOptionWorked(worked PomoControllerWorkedIface) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.workedSink
+		c.workedSink = worked
+		return PomoControllerOptionWorked(prev), nil
+	}
+}
+
+// Sets source of the next scheduled action shown on status. Nil disables it.
+func PomoControllerOptionSchedule(schedule PomoControllerScheduleIface) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.schedule
+		c.schedule = schedule
+		return PomoControllerOptionSchedule(prev), nil
+	}
+}
+
+// Sets the time source used for status. Give the same clock to the timer.
+func PomoControllerOptionClock(clock pomoTimer.Clock) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.clock
+		c.clock = clock
+		return PomoControllerOptionClock(prev), nil
+	}
+}
+
+// Sets what to do when the timer notices a clock jump.
+func PomoControllerOptionJumpPolicy(policy PomoControllerJumpPolicy) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.jumpPolicy
+		c.jumpPolicy = policy
+		return PomoControllerOptionJumpPolicy(prev), nil
+	}
+}
+
+// Sets offsets before the end of state to warn at.
+func PomoControllerOptionWarnings(warnings PomoControllerWarnings) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.warnings
+		c.warnings = warnings
+		return PomoControllerOptionWarnings(prev), nil
+	}
+}
+
+// Sets the timer used for warnings from factory. A PomoTimer on the
+// controller clock is used if not set.
+func PomoControllerWarningTimerOpt(timerF func() pomoTimer.PomoTimerIface) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.warningTimer
+		c.warningTimer = timerF()
+		return func(c *PomoController) (PomoControllerOption, error) {
+			c.warningTimer = prev
+			return PomoControllerWarningTimerOpt(timerF), nil
+		}, nil
+	}
+}
+
+// Adds warning sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionWarningSink(
+	warningEventSink func(event PomoControllerEventArgsWarning),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Warning: warningEventSink})
+}
+
+// Adds error sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionErrorSink(
+	errorSink func(err error),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Error: errorSink})
+}
+
+// Adds play sink. Same as PomoControllerOptionListener.
 func PomoControllerOptionPlaySink(
 	playEventSink func(event PomoControllerEventArgsPlay),
 ) PomoControllerOption {
-	return func(c *PomoController) (PomoControllerOption, error) {
-		prev := c.playEventSink
-		c.playEventSink = playEventSink
-		return PomoControllerOptionPlaySink(prev), nil
-	}
+	return PomoControllerOptionListener(PomoControllerListener{Play: playEventSink})
 }
 
-// Sets stop sinks
+// Adds stop sink. Same as PomoControllerOptionListener.
 func PomoControllerOptionStopSink(
 	stopEventSink func(event PomoControllerEventArgsStop),
 ) PomoControllerOption {
-	return func(c *PomoController) (PomoControllerOption, error) {
-		prev := c.stopEventSink
-		c.stopEventSink = stopEventSink
-		return PomoControllerOptionStopSink(prev), nil
-	}
+	return PomoControllerOptionListener(PomoControllerListener{Stop: stopEventSink})
 }
 
-// Sets pause sinks
+// Adds pause sink. Same as PomoControllerOptionListener.
 func PomoControllerOptionPauseSink(
 	pauseEventSink func(event PomoControllerEventArgsPause),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Pause: pauseEventSink})
+}
+
+// Adds label sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionLabelSink(
+	labelEventSink func(event PomoControllerEventArgsLabel),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Label: labelEventSink})
+}
+
+// Adds extend sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionExtendSink(
+	extendEventSink func(event PomoControllerEventArgsExtend),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Extend: extendEventSink})
+}
+
+// Adds overtime sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionOvertimeSink(
+	overtimeEventSink func(event PomoControllerEventArgsOvertime),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Overtime: overtimeEventSink})
+}
+
+// Sets which transitions wait for play.
+func PomoControllerOptionAdvancePolicy(
+	policy PomoControllerAdvancePolicy,
 ) PomoControllerOption {
 	return func(c *PomoController) (PomoControllerOption, error) {
-		prev := c.pauseEventSink
-		c.pauseEventSink = pauseEventSink
-		return PomoControllerOptionPauseSink(prev), nil
+		prev := c.advancePolicy
+		c.advancePolicy = policy
+		return PomoControllerOptionAdvancePolicy(prev), nil
 	}
 }
 
-// Sets next state sinks
-func PomoControllerOptionNextStateSink(
-	endOfStateEventSink func(event PomoControllerEventArgsNextState),
+// Sets whether work is open ended: it counts up until play or skip starts the
+// break. Work duration is only a minimum then.
+func PomoControllerOptionOpenWork(open bool) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.openWork
+		c.openWork = open
+		return PomoControllerOptionOpenWork(prev), nil
+	}
+}
+
+// Adds interrupt sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionInterruptSink(
+	interruptEventSink func(event PomoControllerEventArgsInterrupt),
 ) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Interrupt: interruptEventSink})
+}
+
+// Sets number of interruptions a work interval tolerates before starting over.
+func PomoControllerOptionVoidThreshold(threshold int) PomoControllerOption {
 	return func(c *PomoController) (PomoControllerOption, error) {
-		prev := c.endOfStateEventSink
-		c.endOfStateEventSink = endOfStateEventSink
-		return PomoControllerOptionNextStateSink(prev), nil
+		prev := c.voidThreshold
+		c.voidThreshold = threshold
+		return PomoControllerOptionVoidThreshold(prev), nil
 	}
 }
 
-// Create an event listener that runs command on every event
-func PomoControllerHook(command string) PomoControllerOption {
-	// Check that the command is reacheble and executable
+// Adds goal sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionGoalSink(
+	goalEventSink func(event PomoControllerEventArgsGoal),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{Goal: goalEventSink})
+}
 
+// Sets daily goal of work sessions. Nil disables it.
+func PomoControllerOptionDailyGoal(goal *DailyGoal) PomoControllerOption {
 	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.goal
+		c.goal = goal
+		return PomoControllerOptionDailyGoal(prev), nil
+	}
+}
 
-		prevPlay := c.playEventSink
-		prevStop := c.stopEventSink
-		prevPause := c.pauseEventSink
-		prevNe := c.endOfStateEventSink
-		prevErr := c.errorSink
+// Sets snapshot sink. Called with the controller state after every transition.
+func PomoControllerOptionSnapshotSink(
+	snapshotSink func(snapshot PomoControllerSnapshot),
+) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		prev := c.snapshotSink
+		c.snapshotSink = snapshotSink
+		return PomoControllerOptionSnapshotSink(prev), nil
+	}
+}
 
-		c.playEventSink = PlayExecHook(command)
-		c.stopEventSink = StopExecHook(command)
-		c.pauseEventSink = PauseExecHook(command)
-		c.endOfStateEventSink = NextStateExecHook(command)
-		c.errorSink = ErrorExecHook(command)
+// Adds next state sink. Same as PomoControllerOptionListener.
+func PomoControllerOptionNextStateSink(
+	endOfStateEventSink func(event PomoControllerEventArgsNextState),
+) PomoControllerOption {
+	return PomoControllerOptionListener(PomoControllerListener{NextState: endOfStateEventSink})
+}
 
+// Adds sink run on every event on its own goroutine. Slow sinks never hold
+// the controller. Undo unsubscribes it.
+func PomoControllerOptionSubscriber(
+	sink func(event PomoControllerEvent),
+) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		unsubscribe := c.events.Subscribe(sink)
 		return func(c *PomoController) (PomoControllerOption, error) {
-			c.playEventSink = prevPlay
-			c.stopEventSink = prevStop
-			c.pauseEventSink = prevPause
-			c.endOfStateEventSink = prevNe
-			c.errorSink = prevErr
+			unsubscribe()
+			return PomoControllerOptionSubscriber(sink), nil
+		}, nil
+	}
+}
 
-			return PomoControllerHook(command), nil
+// Adds sink run on every event under the controller lock. Only for quick
+// callbacks that must see the event before the controller goes on, like the
+// time worked before the break starts. Undo unsubscribes it.
+func PomoControllerOptionSyncSubscriber(
+	sink func(event PomoControllerEvent),
+) PomoControllerOption {
+	return func(c *PomoController) (PomoControllerOption, error) {
+		unsubscribe := c.events.SubscribeSync(sink)
+		return func(c *PomoController) (PomoControllerOption, error) {
+			unsubscribe()
+			return PomoControllerOptionSyncSubscriber(sink), nil
 		}, nil
 	}
 }
+
+// Adds listener callbacks under the controller lock. Use
+// PomoControllerOptionSubscriber with PomoControllerListener.Sink for slow
+// listeners.
+func PomoControllerOptionListener(l PomoControllerListener) PomoControllerOption {
+	return PomoControllerOptionSyncSubscriber(l.Sink())
+}
+
+// Create an event listener that runs command on every event
+func PomoControllerHook(command string) PomoControllerOption {
+	return PomoControllerHookCtx(context.Background(), command)
+}
+
+// Same as PomoControllerHook. Running commands are killed once ctx is done.
+func PomoControllerHookCtx(ctx context.Context, command string) PomoControllerOption {
+	return PomoControllerOptionHookRunner(ctx, &HookRunner{}, command)
+}
+
+// Runs command on every event through runner. Hooks sharing a runner share
+// its limits. Queuing never blocks so it's done right away, in event order.
+func PomoControllerOptionHookRunner(
+	ctx context.Context,
+	runner *HookRunner,
+	command string,
+) PomoControllerOption {
+	return PomoControllerOptionHook(ctx, runner, Hook{Command: command})
+}
+
+// Same as PomoControllerOptionHookRunner for hooks bound to some events or
+// directories of scripts.
+func PomoControllerOptionHook(
+	ctx context.Context,
+	runner *HookRunner,
+	hook Hook,
+) PomoControllerOption {
+	return PomoControllerOptionSyncSubscriber(runner.HookSink(ctx, hook))
+}
@@ -0,0 +1,83 @@
+// Daily goal of completed work sessions.
+
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// Count of completed work sessions on a calendar day. Days start at
+// RolloverHour on Location so late night sessions may count for the day
+// before. It survives stop and play cycles.
+type DailyGoal struct {
+	Target       int
+	Location     *time.Location
+	RolloverHour int
+
+	day     string
+	done    int
+	reached bool
+	locker  sync.Mutex
+}
+
+func (g *DailyGoal) dayKey(t time.Time) string {
+	loc := g.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	shifted := t.In(loc).Add(-time.Duration(g.RolloverHour) * time.Hour)
+	return shifted.Format(time.DateOnly)
+}
+
+// Start counting from zero if the day changed. Must be called with the lock.
+func (g *DailyGoal) rollover(now time.Time) {
+	day := g.dayKey(now)
+	if day == g.day {
+		return
+	}
+	g.day = day
+	g.done = 0
+	g.reached = false
+}
+
+// Count a completed work session. True only the first time the target is
+// reached on the day.
+func (g *DailyGoal) Record(now time.Time) bool {
+	g.locker.Lock()
+	defer g.locker.Unlock()
+
+	g.rollover(now)
+	g.done++
+	if g.reached || g.done < g.Target {
+		return false
+	}
+	g.reached = true
+	return true
+}
+
+func (g *DailyGoal) Progress(now time.Time) PomoControllerGoalProgress {
+	g.locker.Lock()
+	defer g.locker.Unlock()
+
+	g.rollover(now)
+	return PomoControllerGoalProgress{
+		Day:     g.day,
+		Target:  g.Target,
+		Done:    g.done,
+		Reached: g.reached,
+	}
+}
+
+// Set progress from snapshot. Ignored if it belongs to another day.
+func (g *DailyGoal) Restore(now time.Time, progress PomoControllerGoalProgress) {
+	g.locker.Lock()
+	defer g.locker.Unlock()
+
+	g.rollover(now)
+	if progress.Day != g.day {
+		return
+	}
+	g.done = progress.Done
+	g.reached = g.done >= g.Target
+}
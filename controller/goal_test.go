@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+)
+
+func TestDailyGoalRollover(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	goal := DailyGoal{Target: 2, Location: loc, RolloverHour: 4}
+
+	// 01:30 LOCAL TIME COUNTS FOR THE DAY BEFORE.
+	lateNight := time.Date(2024, 12, 6, 23, 30, 0, 0, time.UTC)
+	if goal.Record(lateNight) {
+		t.Fatalf("Goal reached after one session")
+	}
+	if day := goal.Progress(lateNight).Day; day != "2024-12-06" {
+		t.Fatalf("Day is %s, expected 2024-12-06", day)
+	}
+
+	if !goal.Record(lateNight.Add(time.Hour)) {
+		t.Fatalf("Goal not reached after two sessions")
+	}
+
+	// ONLY FIRST TIME.
+	if goal.Record(lateNight.Add(90 * time.Minute)) {
+		t.Fatalf("Goal reached twice on the same day")
+	}
+
+	// 06:30 LOCAL TIME IS A NEW DAY.
+	morning := lateNight.Add(5 * time.Hour)
+	progress := goal.Progress(morning)
+	if progress.Day != "2024-12-07" || progress.Done != 0 || progress.Reached {
+		t.Fatalf("Unexpected progress on new day %+v", progress)
+	}
+}
+
+func TestControllerGoalEvent(t *testing.T) {
+
+	refNow := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	timer := &pomoTimer.MockCbTimer{}
+	session := sessionFactory()
+
+	goalEvents := []PomoControllerEventArgsGoal{}
+	goalSink := func(event PomoControllerEventArgsGoal) {
+		goalEvents = append(goalEvents, event)
+	}
+
+	controller, err := mockControllerFactory(
+		timer,
+		session,
+		PomoControllerOptionDailyGoal(&DailyGoal{Target: 2, Location: time.UTC}),
+		PomoControllerOptionGoalSink(goalSink),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+
+	// SKIPPED WORK DOES NOT COUNT.
+	if err := controller.Skip(refNow); err != nil {
+		t.Fatal(err)
+	}
+
+	// BREAK AND FIRST COMPLETED WORK.
+	for i := 0; i < 2; i++ {
+		if err := timer.ForceDone(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// PROGRESS SURVIVES STOP AND PLAY.
+	if err := controller.Stop(refNow); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := controller.Play(refNow); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(goalEvents) != 0 {
+		t.Fatalf("Goal reached too early %+v", goalEvents)
+	}
+
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(goalEvents) != 1 || goalEvents[0].Progress.Done != 2 {
+		t.Fatalf("Expected one goal event with 2 sessions done, got %+v", goalEvents)
+	}
+}
+
+// WORK IN OVERTIME IS DONE. IT COUNTS ONCE, WHEN THE OVERTIME BEGINS.
+func TestControllerGoalOvertime(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	eos := start.Add(25 * time.Minute)
+	timer := &pomoTimer.MockCbTimer{}
+
+	goalEvents := []PomoControllerEventArgsGoal{}
+	ctrl := fakeClockControllerFactory(
+		t,
+		pomoTimer.NewFakeClock(start),
+		timerOpt(timer),
+		PomoControllerOptionAdvancePolicy(PomoControllerAdvancePolicy{ManualAfterWork: true}),
+		PomoControllerOptionDailyGoal(&DailyGoal{Target: 1, Location: time.UTC}),
+		PomoControllerOptionGoalSink(func(event PomoControllerEventArgsGoal) {
+			goalEvents = append(goalEvents, event)
+		}),
+	)
+
+	if err := ctrl.Play(start); err != nil {
+		t.Fatal(err)
+	}
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+	if len(goalEvents) != 1 || !goalEvents[0].At.Equal(eos) {
+		t.Fatalf("Expected goal reached at %s, got %+v", eos, goalEvents)
+	}
+
+	// LEAVE OVERTIME AND REACH IT AGAIN.
+	if err := ctrl.Extend(eos.Add(5*time.Minute), 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctrl.Stop(eos.Add(20 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if done := ctrl.goal.Progress(eos).Done; done != 1 || len(goalEvents) != 1 {
+		t.Fatalf("Goal done is %d with %d events, expected 1 and 1", done, len(goalEvents))
+	}
+}
@@ -1,93 +1,541 @@
-// Common logic on what to do on error
-// TODO: IMPROVE ON ERROR MANAGEMENT. Hooks should return an error. This code should not output to stderr.
+// Exec hooks. Commands run asynchronously through a runner that keeps them in
+// order, bounds them in time and number and reports their failures.
 
 package controller
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Output kept of every stream of a hook. The rest is dropped.
+const HookOutputLimit = 64 * 1024
+
+// Time a killed hook is given to release its output before giving up on it.
+const hookWaitDelay = time.Second
+
+// ====
+// HOOK
+// ====
+
+// Names of hook events, as in POMOGO_EVENT.
+var HookEvents = []string{
+	"Play",
+	"Stop",
+	"Pause",
+	"EndOfState",
+	"Label",
+	"Extend",
+	"Overtime",
+	"Interrupt",
+	"GoalReached",
+	"Warning",
+	"Error",
+}
+
+// Controller event type names that differ from their hook event.
+var hookEventAliases = map[string]string{
+	PomoControllerEventTypeNextState.String(): "EndOfState",
+	PomoControllerEventTypeGoal.String():      "GoalReached",
+}
+
+// Script or directory of scripts run on some events. Scripts of Dir run one
+// after the other in lexical order, run-parts style. It is read again on every
+// event so scripts may be added or removed while running.
+type Hook struct {
+	Command string
+	Dir     string
+	// Hook event names it runs on. Every event if empty.
+	Events []string
+}
+
+// Whether the hook runs on the event.
+func (h Hook) Matches(event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Return hook event name regardless of case, like endofstate. Controller event
+// type names, like goal, are accepted too.
+func ParseHookEvent(s string) (string, error) {
+	for _, event := range HookEvents {
+		if strings.EqualFold(event, s) {
+			return event, nil
+		}
+	}
+	for alias, event := range hookEventAliases {
+		if strings.EqualFold(alias, s) {
+			return event, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidHookEvent, s)
+}
+
+// Parse hooks like play=./start.sh or play,pause=./timew.sh.
+func ParseHook(s string) (Hook, error) {
+	eventsText, command, ok := strings.Cut(s, "=")
+	if !ok || command == "" || eventsText == "" {
+		return Hook{}, fmt.Errorf("%w: %s", ErrInvalidHook, s)
+	}
+
+	hook := Hook{Command: command}
+	for _, text := range strings.Split(eventsText, ",") {
+		event, err := ParseHookEvent(strings.TrimSpace(text))
+		if err != nil {
+			return Hook{}, err
+		}
+		hook.Events = append(hook.Events, event)
+	}
+	return hook, nil
+}
+
+// Executable scripts of dir in lexical order. Hidden files, backups ending in
+// ~ and directories are left out.
+func HookDirCommands(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+
+		// FOLLOWS SYMLINKS.
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		commands = append(commands, filepath.Join(dir, name))
+	}
+	return commands, nil
+}
+
 // ======================
 // COMMAND EXECUTOR LOGIC
 // ======================
 
-func onError(err error) {
-	if err == nil {
+// Process is killed once ctx is done. Payload is written to its stdin and
+// described on its environment.
+func genCommand(
+	ctx context.Context,
+	command string,
+	payload HookPayload,
+) (*exec.Cmd, error) {
+	stdin, err := payload.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(), HookEnv(payload)...)
+	cmd.WaitDelay = hookWaitDelay
+	return cmd, nil
+}
+
+// ===========
+// HOOK RUNNER
+// ===========
+
+// Runs hooks off the caller. Runs of the same command or directory start in
+// order, one after the other. Different ones may run at once up to
+// MaxConcurrent. Zero value runs one hook at a time with no timeout.
+type HookRunner struct {
+	// Hooks running longer are killed. No limit if zero.
+	Timeout time.Duration
+	// Hook processes running at once. One if zero.
+	MaxConcurrent int
+	// Called with every hook failure. Ignored if nil.
+	OnError func(err error)
+
+	slots chan struct{}
+	// Done channel of the last run of every command or directory.
+	last map[string]chan struct{}
+
+	// Runs queued or running.
+	pending int
+	idle    *sync.Cond
+
+	locker sync.Mutex
+}
+
+// Queue command with payload. Never blocks.
+func (r *HookRunner) Run(ctx context.Context, command string, payload HookPayload) {
+	r.queue(command, func() {
+		if err := r.Exec(ctx, command, payload); err != nil {
+			r.errorEvent(err)
+		}
+	})
+}
+
+// Queue every script of dir with payload, one after the other in lexical
+// order. Failures don't stop the scripts after them. Never blocks.
+func (r *HookRunner) RunDir(ctx context.Context, dir string, payload HookPayload) {
+	r.queue(dir, func() {
+		commands, err := HookDirCommands(dir)
+		if err != nil {
+			r.errorEvent(fmt.Errorf("%w: %s: %w", ErrHookFailed, dir, err))
+			return
+		}
+		for _, command := range commands {
+			if err := r.Exec(ctx, command, payload); err != nil {
+				r.errorEvent(err)
+			}
+		}
+	})
+}
+
+// Run command with payload right away and wait for it. Output is logged.
+func (r *HookRunner) Exec(ctx context.Context, command string, payload HookPayload) error {
+	runCtx := ctx
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	cmd, err := genCommand(runCtx, command, payload)
+	if err != nil {
+		return fmt.Errorf("%w: %s on %s: %w", ErrHookFailed, command, payload.Event, err)
+	}
+
+	stdout := &hookOutput{}
+	stderr := &hookOutput{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	logHookOutput(command, payload, stdout, stderr, time.Since(start))
+
+	// KILLED BY ITS OWN TIMEOUT, NOT BY THE CALLER.
+	if ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s on %s after %s", ErrHookTimeout, command, payload.Event, r.Timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s on %s: %w", ErrHookFailed, command, payload.Event, err)
+	}
+	return nil
+}
+
+// Wait until every hook queued so far is done.
+func (r *HookRunner) Wait() {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	r.init()
+	for r.pending > 0 {
+		r.idle.Wait()
+	}
+}
+
+// Event bus sink running command on every event.
+func (r *HookRunner) Sink(ctx context.Context, command string) func(event PomoControllerEvent) {
+	return r.HookSink(ctx, Hook{Command: command})
+}
+
+// Event bus sink running hook on its events.
+func (r *HookRunner) HookSink(ctx context.Context, hook Hook) func(event PomoControllerEvent) {
+	return func(event PomoControllerEvent) {
+		payload, ok := NewHookPayload(event)
+		if !ok || !hook.Matches(payload.Event) {
+			return
+		}
+		if hook.Dir != "" {
+			r.RunDir(ctx, hook.Dir, payload)
+			return
+		}
+		r.Run(ctx, hook.Command, payload)
+	}
+}
+
+// -------
+// HELPERS
+// -------
+
+// Run job after the previous one of key, once a slot is free.
+func (r *HookRunner) queue(key string, job func()) {
+	r.locker.Lock()
+	r.init()
+	prev := r.last[key]
+	done := make(chan struct{})
+	r.last[key] = done
+	r.pending++
+	r.locker.Unlock()
+
+	go func() {
+		defer r.finish(key, done)
+
+		// IN ORDER PER KEY.
+		if prev != nil {
+			<-prev
+		}
+
+		r.slots <- struct{}{}
+		defer func() { <-r.slots }()
+
+		job()
+	}()
+}
+
+// Call with lock.
+func (r *HookRunner) init() {
+	if r.idle != nil {
 		return
 	}
-	fmt.Fprint(os.Stderr, err)
+	r.idle = sync.NewCond(&r.locker)
+	r.last = make(map[string]chan struct{})
+	r.slots = make(chan struct{}, max(r.MaxConcurrent, 1))
 }
 
-func genCommand(command string, at time.Time, status string, eventType string) *exec.Cmd {
-	cmd := exec.Command(command)
-	cmd.Env = append(
-		os.Environ(),
-		"POMOGO_AT="+at.String(),
-		"POMOGO_STATUS="+status,
-		"POMO_EVENT="+eventType,
-	)
-	return cmd
+func (r *HookRunner) finish(key string, done chan struct{}) {
+	close(done)
+
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	if r.last[key] == done {
+		delete(r.last, key)
+	}
+	r.pending--
+	if r.pending == 0 {
+		r.idle.Broadcast()
+	}
+}
+
+func (r *HookRunner) errorEvent(err error) {
+	if r.OnError == nil {
+		return
+	}
+	r.OnError(err)
+}
+
+func logHookOutput(
+	command string,
+	payload HookPayload,
+	stdout, stderr *hookOutput,
+	elapsed time.Duration,
+) {
+	attrs := []any{"command", command, "event", payload.Event, "elapsed", elapsed}
+	if stdout.Len() > 0 {
+		slog.Info("Hook stdout", append(attrs, "output", stdout.String())...)
+	}
+	if stderr.Len() > 0 {
+		slog.Warn("Hook stderr", append(attrs, "output", stderr.String())...)
+	}
+}
+
+// Buffer dropping everything past HookOutputLimit.
+type hookOutput struct {
+	bytes.Buffer
+	truncated bool
+}
+
+func (o *hookOutput) Write(p []byte) (int, error) {
+	room := HookOutputLimit - o.Buffer.Len()
+	if len(p) > room {
+		o.truncated = true
+		o.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return o.Buffer.Write(p)
 }
 
+func (o *hookOutput) String() string {
+	if o.truncated {
+		return o.Buffer.String() + "... (truncated)"
+	}
+	return o.Buffer.String()
+}
+
+// ==========
+// EXEC HOOKS
+// ==========
+
+// Shared by every exec hook so they queue in order without blocking the
+// caller. Failures are only logged.
+var execHookRunner = &HookRunner{
+	OnError: func(err error) {
+		slog.Error("Hook failed", "err", err)
+	},
+}
+
+// Queue command with payload. Never blocks.
+func execHook(ctx context.Context, command string, payload HookPayload) {
+	execHookRunner.Run(ctx, command, payload)
+}
+
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
 func PlayExecHook(command string) func(event PomoControllerEventArgsPlay) {
+	return PlayExecHookCtx(context.Background(), command)
+}
+
+// Same as PlayExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func PlayExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsPlay) {
 	return func(event PomoControllerEventArgsPlay) {
-		cmd := genCommand(
-			command,
-			event.At,
-			event.CurrentState.String(),
-			"Play",
-		)
-		go onError(cmd.Run())
+		execHook(ctx, command, playPayload(event))
 	}
 }
 
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
 func StopExecHook(command string) func(event PomoControllerEventArgsStop) {
+	return StopExecHookCtx(context.Background(), command)
+}
+
+// Same as StopExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func StopExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsStop) {
 	return func(event PomoControllerEventArgsStop) {
-		cmd := genCommand(
-			command,
-			event.At,
-			event.CurrentState.String(),
-			"Stop",
-		)
-		go onError(cmd.Run())
+		execHook(ctx, command, stopPayload(event))
 	}
 }
 
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
 func PauseExecHook(command string) func(event PomoControllerEventArgsPause) {
+	return PauseExecHookCtx(context.Background(), command)
+}
+
+// Same as PauseExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func PauseExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsPause) {
 	return func(event PomoControllerEventArgsPause) {
-		cmd := genCommand(
-			command,
-			event.At,
-			event.CurrentState.String(),
-			"Pause",
-		)
-		go onError(cmd.Run())
+		execHook(ctx, command, pausePayload(event))
 	}
 }
 
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
 func NextStateExecHook(command string) func(event PomoControllerEventArgsNextState) {
+	return NextStateExecHookCtx(context.Background(), command)
+}
+
+// Same as NextStateExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func NextStateExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsNextState) {
 	return func(event PomoControllerEventArgsNextState) {
-		cmd := genCommand(
-			command,
-			event.At,
-			event.NextState.String(),
-			"EndOfState",
-		)
-		go onError(cmd.Run())
+		execHook(ctx, command, endOfStatePayload(event))
 	}
 }
 
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func LabelExecHook(command string) func(event PomoControllerEventArgsLabel) {
+	return LabelExecHookCtx(context.Background(), command)
+}
+
+// Same as LabelExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func LabelExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsLabel) {
+	return func(event PomoControllerEventArgsLabel) {
+		execHook(ctx, command, labelPayload(event))
+	}
+}
+
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func ExtendExecHook(command string) func(event PomoControllerEventArgsExtend) {
+	return ExtendExecHookCtx(context.Background(), command)
+}
+
+// Same as ExtendExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func ExtendExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsExtend) {
+	return func(event PomoControllerEventArgsExtend) {
+		execHook(ctx, command, extendPayload(event))
+	}
+}
+
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func OvertimeExecHook(command string) func(event PomoControllerEventArgsOvertime) {
+	return OvertimeExecHookCtx(context.Background(), command)
+}
+
+// Same as OvertimeExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func OvertimeExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsOvertime) {
+	return func(event PomoControllerEventArgsOvertime) {
+		execHook(ctx, command, overtimePayload(event))
+	}
+}
+
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func InterruptExecHook(command string) func(event PomoControllerEventArgsInterrupt) {
+	return InterruptExecHookCtx(context.Background(), command)
+}
+
+// Same as InterruptExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func InterruptExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsInterrupt) {
+	return func(event PomoControllerEventArgsInterrupt) {
+		execHook(ctx, command, interruptPayload(event))
+	}
+}
+
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func GoalExecHook(command string) func(event PomoControllerEventArgsGoal) {
+	return GoalExecHookCtx(context.Background(), command)
+}
+
+// Same as GoalExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func GoalExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsGoal) {
+	return func(event PomoControllerEventArgsGoal) {
+		execHook(ctx, command, goalPayload(event))
+	}
+}
+
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func WarningExecHook(command string) func(event PomoControllerEventArgsWarning) {
+	return WarningExecHookCtx(context.Background(), command)
+}
+
+// Same as WarningExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func WarningExecHookCtx(ctx context.Context, command string) func(event PomoControllerEventArgsWarning) {
+	return func(event PomoControllerEventArgsWarning) {
+		execHook(ctx, command, warningPayload(event))
+	}
+}
+
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
 func ErrorExecHook(command string) func(event error) {
+	return ErrorExecHookCtx(context.Background(), command)
+}
+
+// Same as ErrorExecHook. Running commands are killed once ctx is done.
+//
+// Deprecated: Use HookRunner.Exec with NewHookPayload.
+func ErrorExecHookCtx(ctx context.Context, command string) func(event error) {
 	return func(event error) {
-		cmd := genCommand(
-			command,
-			time.Now(),
-			event.Error(),
-			"Error",
-		)
-		go onError(cmd.Run())
+		execHook(ctx, command, errorPayload(time.Now(), event))
 	}
 }
@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+func writeHook(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	script := filepath.Join(dir, name)
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func hookEvent(event string) HookPayload {
+	return newHookPayload(event, time.Now(), HookDataError{})
+}
+
+// Collects runner errors.
+type hookErrors struct {
+	errs   []error
+	locker sync.Mutex
+}
+
+func (e *hookErrors) sink(err error) {
+	e.locker.Lock()
+	defer e.locker.Unlock()
+	e.errs = append(e.errs, err)
+}
+
+// =====
+// TESTS
+// =====
+
+func TestHookRunnerOrder(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "events")
+	// EARLIER EVENTS TAKE LONGER. ORDER ONLY HOLDS IF RUNS WAIT FOR EACH OTHER.
+	script := writeHook(t, dir, "hook.sh",
+		"[ \"$POMOGO_EVENT\" = Play ] && sleep 0.2\necho \"$POMOGO_EVENT\" >> "+out)
+
+	runner := &HookRunner{MaxConcurrent: 4}
+	for _, event := range []string{"Play", "Pause", "Stop"} {
+		runner.Run(context.Background(), script, hookEvent(event))
+	}
+	runner.Wait()
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "Play\nPause\nStop\n" {
+		t.Fatalf("Expected hooks in order, got %q", b)
+	}
+}
+
+func TestHookRunnerTimeout(t *testing.T) {
+	script := writeHook(t, t.TempDir(), "hook.sh", "sleep 30")
+
+	errs := &hookErrors{}
+	runner := &HookRunner{Timeout: 100 * time.Millisecond, OnError: errs.sink}
+
+	start := time.Now()
+	runner.Run(context.Background(), script, hookEvent("Play"))
+	runner.Wait()
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Expected hook to be killed on timeout, took %s", elapsed)
+	}
+	if len(errs.errs) != 1 || !errors.Is(errs.errs[0], ErrHookTimeout) {
+		t.Fatalf("Expected a timeout error, got %v", errs.errs)
+	}
+}
+
+// RUNS OF DIFFERENT COMMANDS OVERLAP UP TO THE LIMIT.
+func TestHookRunnerMaxConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	runner := &HookRunner{MaxConcurrent: 2}
+
+	start := time.Now()
+	for _, name := range []string{"a.sh", "b.sh", "c.sh", "d.sh"} {
+		script := writeHook(t, dir, name, "sleep 0.3")
+		runner.Run(context.Background(), script, hookEvent("Play"))
+	}
+	runner.Wait()
+
+	// TWO ROUNDS OF TWO.
+	if elapsed := time.Since(start); elapsed < 550*time.Millisecond {
+		t.Fatalf("Expected at most two hooks at once, all done in %s", elapsed)
+	}
+}
+
+func TestHookRunnerFailure(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	script := writeHook(t, t.TempDir(), "hook.sh", "echo on stdout\necho on stderr >&2\nexit 3")
+
+	errs := &hookErrors{}
+	runner := &HookRunner{OnError: errs.sink}
+	runner.Run(context.Background(), script, hookEvent("Stop"))
+	runner.Wait()
+
+	if len(errs.errs) != 1 || !errors.Is(errs.errs[0], ErrHookFailed) {
+		t.Fatalf("Expected a hook error, got %v", errs.errs)
+	}
+	if !strings.Contains(errs.errs[0].Error(), "on Stop") {
+		t.Fatalf("Expected error to name the event, got %s", errs.errs[0])
+	}
+
+	for _, expected := range []string{"on stdout", "on stderr", "event=Stop"} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Fatalf("Expected %q in logs, got %s", expected, logs.String())
+		}
+	}
+}
+
+func TestHookOutputLimit(t *testing.T) {
+	out := &hookOutput{}
+	chunk := bytes.Repeat([]byte("x"), HookOutputLimit/2+1)
+	for i := 0; i < 3; i++ {
+		if n, err := out.Write(chunk); err != nil || n != len(chunk) {
+			t.Fatalf("Expected whole writes to succeed, got %d %v", n, err)
+		}
+	}
+	if out.Len() != HookOutputLimit {
+		t.Fatalf("Expected output capped at %d, got %d", HookOutputLimit, out.Len())
+	}
+	if !strings.HasSuffix(out.String(), "(truncated)") {
+		t.Fatalf("Expected truncated output to say so")
+	}
+}
+
+func TestParseHook(t *testing.T) {
+	hook, err := ParseHook("endofstate, Stop=./notify.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hook.Command != "./notify.sh" || len(hook.Events) != 2 ||
+		hook.Events[0] != "EndOfState" || hook.Events[1] != "Stop" {
+		t.Fatalf("Unexpected hook %+v", hook)
+	}
+	if !hook.Matches("Stop") || hook.Matches("Play") {
+		t.Fatalf("Unexpected matches of %+v", hook)
+	}
+
+	// CONTROLLER EVENT TYPE NAMES ARE THE SAME HOOK EVENTS.
+	hook, err = ParseHook("goal,nextstate=./notify.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.Events) != 2 || hook.Events[0] != "GoalReached" || hook.Events[1] != "EndOfState" {
+		t.Fatalf("Unexpected hook %+v", hook)
+	}
+
+	for _, text := range []string{"", "play", "=./a.sh", "play=", "start=./a.sh"} {
+		if _, err := ParseHook(text); err == nil {
+			t.Fatalf("Expected error parsing %q", text)
+		}
+	}
+}
+
+func TestHookDirCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "20-notify", "true")
+	writeHook(t, dir, "10-timew.sh", "true")
+	writeHook(t, dir, ".hidden", "true")
+	writeHook(t, dir, "30-old~", "true")
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("docs"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "40-dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, err := HookDirCommands(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{filepath.Join(dir, "10-timew.sh"), filepath.Join(dir, "20-notify")}
+	if len(commands) != len(expected) || commands[0] != expected[0] || commands[1] != expected[1] {
+		t.Fatalf("Expected %v, got %v", expected, commands)
+	}
+}
+
+// SCRIPTS OF A DIRECTORY RUN ONE AFTER THE OTHER, EVEN IF SLOWER.
+func TestHookRunnerDir(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(t.TempDir(), "runs")
+	writeHook(t, dir, "10-first", "sleep 0.2\necho first >> "+out)
+	writeHook(t, dir, "20-failing", "exit 1")
+	writeHook(t, dir, "30-last", "echo last >> "+out)
+
+	errs := &hookErrors{}
+	runner := &HookRunner{MaxConcurrent: 4, OnError: errs.sink}
+	runner.RunDir(context.Background(), dir, hookEvent("Play"))
+	runner.Wait()
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "first\nlast\n" {
+		t.Fatalf("Expected scripts in lexical order, got %q", b)
+	}
+	if len(errs.errs) != 1 || !errors.Is(errs.errs[0], ErrHookFailed) {
+		t.Fatalf("Expected the failing script to be reported, got %v", errs.errs)
+	}
+}
+
+func TestControllerHookEvents(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "events")
+	script := writeHook(t, dir, "hook.sh", "echo \"$POMOGO_EVENT\" >> "+out)
+
+	runner := &HookRunner{}
+	controller, err := mockControllerFactory(
+		&checkTimer{},
+		sessionFactory(),
+		PomoControllerOptionHook(
+			context.Background(),
+			runner,
+			Hook{Command: script, Events: []string{"Stop"}},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if err := controller.Play(now); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Stop(now); err != nil {
+		t.Fatal(err)
+	}
+	runner.Wait()
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "Stop\n" {
+		t.Fatalf("Expected only the stop event, got %q", b)
+	}
+}
+
+// DEPRECATED EXEC HOOKS ARE QUEUED, NOT WAITED FOR.
+func TestExecHookNonBlocking(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	script := writeHook(t, t.TempDir(), "slow.sh", "sleep 10")
+
+	start := time.Now()
+	PlayExecHookCtx(ctx, script)(PomoControllerEventArgsPlay{At: start})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected exec hook to return right away, took %s", elapsed)
+	}
+
+	cancel()
+	execHookRunner.Wait()
+}
@@ -2,14 +2,38 @@
 
 package controller
 
-import "time"
+import (
+	"context"
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	"time"
+)
 
 type PomoControllerIface interface {
 	Status() PomoControllerStatus
 	Pause(now time.Time) error
 	Play(now time.Time) error
+	PlayLabel(now time.Time, label SessionLabel) error
 	Skip(now time.Time) error
+	SkipTo(now time.Time, status pomoSession.PomoSessionStatus) error
 	Stop(now time.Time) error
+	Label(now time.Time, label SessionLabel) error
+	Extend(now time.Time, delta time.Duration) error
+	Interrupt(now time.Time, kind PomoInterruptionKind, note string) error
+	Tick(now time.Time) PomoControllerTick
+	SubscribeTicks(every time.Duration, sink func(tick PomoControllerTick)) (func(), error)
+	Subscribe(sink func(event PomoControllerEvent)) (unsubscribe func())
+
+	// SAME AS ABOVE BUT FAIL WITH THE CONTEXT ERROR ONCE IT IS DONE.
+	PauseCtx(ctx context.Context, now time.Time) error
+	PlayCtx(ctx context.Context, now time.Time) error
+	PlayLabelCtx(ctx context.Context, now time.Time, label SessionLabel) error
+	SkipCtx(ctx context.Context, now time.Time) error
+	SkipToCtx(ctx context.Context, now time.Time, status pomoSession.PomoSessionStatus) error
+	StopCtx(ctx context.Context, now time.Time) error
+	LabelCtx(ctx context.Context, now time.Time, label SessionLabel) error
+	ExtendCtx(ctx context.Context, now time.Time, delta time.Duration) error
+	InterruptCtx(ctx context.Context, now time.Time, kind PomoInterruptionKind, note string) error
+	SubscribeTicksCtx(ctx context.Context, every time.Duration, sink func(tick PomoControllerTick)) (func(), error)
 }
 
 // Manages lifecycle of controller object.
@@ -18,6 +42,109 @@ type PomoControllerContainerIface interface {
 	RemoveController()
 }
 
+// =====
+// LABEL
+// =====
+
+// What is being worked on. Set through the label action or on play. It is kept
+// for every following interval until it's changed or the controller stops.
+type SessionLabel struct {
+	Task    string
+	Project string
+	Tags    []string
+}
+
+func (l SessionLabel) IsEmpty() bool {
+	return l.Task == "" && l.Project == "" && len(l.Tags) == 0
+}
+
+// ==============
+// ADVANCE POLICY
+// ==============
+
+// Transitions that wait for play instead of starting the next state on their
+// own. Zero value advances automatically.
+type PomoControllerAdvancePolicy struct {
+	ManualAfterWork  bool
+	ManualAfterBreak bool
+}
+
+// Whether the end of the given state waits for play.
+func (p PomoControllerAdvancePolicy) IsManual(status pomoSession.PomoSessionStatus) bool {
+	if status == pomoSession.PomoSessionWork {
+		return p.ManualAfterWork
+	}
+	return p.ManualAfterBreak
+}
+
+// ===========
+// TIME WORKED
+// ===========
+
+// Durations that depend on the time worked right before, like flowtime
+// breaks. Told before the break duration is asked for, including on catch up
+// and restore.
+type PomoControllerWorkedIface interface {
+	SetWorked(worked time.Duration)
+}
+
+// =============
+// INTERRUPTIONS
+// =============
+
+// Interruptions of the current work interval.
+type PomoControllerInterruptions struct {
+	Internal int
+	External int
+}
+
+func (i PomoControllerInterruptions) Total() int {
+	return i.Internal + i.External
+}
+
+// ====
+// GOAL
+// ====
+
+type PomoControllerGoalProgress struct {
+	Day     string
+	Target  int
+	Done    int
+	Reached bool
+}
+
+// ========
+// SCHEDULE
+// ========
+
+// Action run on its own at a given time, like play every weekday at 9.
+type PomoControllerScheduled struct {
+	At     time.Time
+	Action string
+}
+
+// Source of the next scheduled action.
+type PomoControllerScheduleIface interface {
+	Next(now time.Time) (PomoControllerScheduled, bool)
+}
+
+// ========
+// CALENDAR
+// ========
+
+// Time work is not possible, like a meeting.
+type PomoControllerBusy struct {
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+// Source of busy time like calendars.
+type PomoControllerCalendarIface interface {
+	// First busy time overlapping from to to. Busy at from if both are equal.
+	Busy(from, to time.Time) (PomoControllerBusy, bool)
+}
+
 // ======
 // STATUS
 // ======
@@ -26,7 +153,29 @@ type PomoControllerStatus struct {
 	State          PomoControllerState
 	TimeLeft       *StatusDuration
 	PausedAt       *time.Time
+	Overtime       *StatusDuration
 	WorkedSessions int
+	Label          *SessionLabel
+	Interruptions  PomoControllerInterruptions
+	Goal           *PomoControllerGoalProgress
+	Scheduled      *PomoControllerScheduled
+	// Why it paused. Empty for pauses asked for.
+	PauseReason string
+	// Busy time that shortened or paused the current interval.
+	Busy *PomoControllerBusy
+	// Time worked on open ended work, counting up. Pauses excluded.
+	Elapsed *StatusDuration
+}
+
+// Progress of the current state for live countdowns. Overtime counts as
+// elapsed with nothing left.
+type PomoControllerTick struct {
+	At       time.Time
+	State    PomoControllerState
+	TimeLeft StatusDuration
+	Elapsed  StatusDuration
+	Percent  float64
+	Label    *SessionLabel
 }
 
 // ======
@@ -38,27 +187,134 @@ type PomoControllerEventArgsPlay struct {
 	CurrentState         PomoControllerState
 	NextState            PomoControllerState
 	CurrentStateDuration time.Duration
+	WorkedSessions       int
+	Label                SessionLabel
 }
 
 type PomoControllerEventArgsStop struct {
-	At           time.Time
-	CurrentState PomoControllerState
-	TimeSpent    time.Duration
-	TimeLeft     time.Duration
+	At             time.Time
+	CurrentState   PomoControllerState
+	TimeSpent      time.Duration
+	TimeLeft       time.Duration
+	WorkedSessions int
+	Label          SessionLabel
+	Overtime       time.Duration
 }
 
+// Reason is empty for pauses asked for.
 type PomoControllerEventArgsPause struct {
-	At           time.Time
-	CurrentState PomoControllerState
-	TimeSpent    time.Duration
-	TimeLeft     time.Duration
+	At             time.Time
+	CurrentState   PomoControllerState
+	TimeSpent      time.Duration
+	TimeLeft       time.Duration
+	WorkedSessions int
+	Label          SessionLabel
+	Reason         string
 }
 
+// Caught up end of states happened while the server was down or the system
+// suspended. They are worked out, not seen, so they don't count as work done.
+// Shortened work was cut to end before busy time. It isn't a full pomodoro
+// either.
 type PomoControllerEventArgsNextState struct {
-	At           time.Time
-	CurrentState PomoControllerState
-	NextState    PomoControllerState
-	TimeLeft     time.Duration
+	At             time.Time
+	CurrentState   PomoControllerState
+	NextState      PomoControllerState
+	TimeSpent      time.Duration
+	TimeLeft       time.Duration
+	WorkedSessions int
+	Label          SessionLabel
+	Skipped        bool
+	Overtime       time.Duration
+	CaughtUp       bool
+	Shortened      bool
+}
+
+// Voided means the interruption exceeded the threshold and the work interval
+// started over.
+type PomoControllerEventArgsInterrupt struct {
+	At             time.Time
+	CurrentState   PomoControllerState
+	Kind           PomoInterruptionKind
+	Note           string
+	Interruptions  PomoControllerInterruptions
+	Voided         bool
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+// Daily goal of work sessions reached.
+type PomoControllerEventArgsGoal struct {
+	At             time.Time
+	Progress       PomoControllerGoalProgress
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+// Some time left before the end of the current state.
+type PomoControllerEventArgsWarning struct {
+	At             time.Time
+	CurrentState   PomoControllerState
+	NextState      PomoControllerState
+	TimeLeft       time.Duration
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+// State time is over but next state waits for play.
+type PomoControllerEventArgsOvertime struct {
+	At             time.Time
+	CurrentState   PomoControllerState
+	NextState      PomoControllerState
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+type PomoControllerEventArgsExtend struct {
+	At             time.Time
+	CurrentState   PomoControllerState
+	Delta          time.Duration
+	TimeLeft       time.Duration
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+type PomoControllerEventArgsLabel struct {
+	At             time.Time
+	CurrentState   PomoControllerState
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+// Envelope of every event sent through the event bus. Seq grows by one on
+// every event of the controller. Timestamp is the time the event happened at.
+// Payload is the PomoControllerEventArgs struct of the type, or the error for
+// errors.
+type PomoControllerEvent struct {
+	Type      PomoControllerEventType
+	Seq       uint64
+	Timestamp time.Time
+	Payload   any
+}
+
+// =========
+// LISTENERS
+// =========
+
+// Set of optional event callbacks, one per event type. Any number of them may
+// be subscribed at once.
+type PomoControllerListener struct {
+	Error     func(err error)
+	Play      func(event PomoControllerEventArgsPlay)
+	Stop      func(event PomoControllerEventArgsStop)
+	Pause     func(event PomoControllerEventArgsPause)
+	NextState func(event PomoControllerEventArgsNextState)
+	Label     func(event PomoControllerEventArgsLabel)
+	Extend    func(event PomoControllerEventArgsExtend)
+	Overtime  func(event PomoControllerEventArgsOvertime)
+	Interrupt func(event PomoControllerEventArgsInterrupt)
+	Goal      func(event PomoControllerEventArgsGoal)
+	Warning   func(event PomoControllerEventArgsWarning)
 }
 
 // ===========
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"time"
+
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+)
+
+// Controller for tests on a fake clock: 4 work sessions before the long
+// break, 25/5/15 minutes. Options are applied after these so they may replace
+// any of them, e.g. a mock timer.
+func FakeClockControllerFactory(
+	clock *pomoTimer.FakeClock,
+	options ...PomoControllerOption,
+) (*PomoController, error) {
+	fixedOptions := []PomoControllerOption{
+		PomoControllerSessionOpt(func() pomoSession.PomoSessionIface {
+			return &pomoSession.PomoSession{WorkSessionsBreak: 4}
+		}),
+		PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+			return &pomoTimer.PomoTimer{Clock: clock}
+		}),
+		PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+			return pomoSession.DurationFactory(25*time.Minute, 5*time.Minute, 15*time.Minute)
+		}),
+		PomoControllerOptionClock(clock),
+	}
+	return ControllerFactory(append(fixedOptions, options...)...)
+}
@@ -0,0 +1,405 @@
+package controller
+
+import (
+	"fmt"
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+// Timer that records invariant violations instead of trusting the caller.
+// Callbacks only run on fire.
+type checkTimer struct {
+	pending    func()
+	generation uint64
+	violations []string
+	locker     sync.Mutex
+}
+
+func (t *checkTimer) WaitCb(d time.Duration, cb func()) error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	if t.pending != nil {
+		t.violations = append(t.violations, "two pending timers")
+		return pomoTimer.ErrTimerWaited
+	}
+	t.generation++
+	t.pending = cb
+	return nil
+}
+
+func (t *checkTimer) Cancel() error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	if t.pending == nil {
+		return pomoTimer.ErrTimerNotWaited
+	}
+	t.pending = nil
+	return nil
+}
+
+func (t *checkTimer) isPending() bool {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	return t.pending != nil
+}
+
+// Take pending callback as the timer firing does. Nil if none.
+func (t *checkTimer) take() func() {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	cb := t.pending
+	t.pending = nil
+	return cb
+}
+
+func (t *checkTimer) fire() bool {
+	cb := t.take()
+	if cb == nil {
+		return false
+	}
+	cb()
+	return true
+}
+
+// Reference model. Only states and transitions, no time.
+type controllerModel struct {
+	running bool
+	paused  bool
+	status  pomoSession.PomoSessionStatus
+	worked  int
+	nWork   int
+}
+
+func (m *controllerModel) next() {
+	if m.status != pomoSession.PomoSessionWork {
+		m.status = pomoSession.PomoSessionWork
+		m.worked++
+		return
+	}
+	if (m.worked+1)%m.nWork == 0 {
+		m.status = pomoSession.PomoSessionLongBreak
+		return
+	}
+	m.status = pomoSession.PomoSessionShortBreak
+}
+
+func (m *controllerModel) state() PomoControllerState {
+	switch {
+	case !m.running:
+		return PomoControllerStopped
+	case m.paused:
+		return PomoControllerPause
+	}
+	return SessionToControllerState(m.status)
+}
+
+// Whether the action must succeed.
+func (m *controllerModel) play() bool {
+	switch {
+	case !m.running:
+		m.running = true
+		m.status = pomoSession.PomoSessionWork
+		m.worked = 0
+		return true
+	case m.paused:
+		m.paused = false
+		return true
+	}
+	return false
+}
+
+func (m *controllerModel) pause() bool {
+	if !m.running || m.paused {
+		return false
+	}
+	m.paused = true
+	return true
+}
+
+func (m *controllerModel) skip() bool {
+	if !m.running {
+		return false
+	}
+	m.paused = false
+	m.next()
+	return true
+}
+
+func (m *controllerModel) stop() bool {
+	if !m.running {
+		return false
+	}
+	m.running = false
+	m.paused = false
+	return true
+}
+
+// Whether the timer must be waiting.
+func (m *controllerModel) fire() bool {
+	if !m.running || m.paused {
+		return false
+	}
+	m.next()
+	return true
+}
+
+const (
+	modelOpPlay = iota
+	modelOpPause
+	modelOpSkip
+	modelOpStop
+	modelOpFire
+	modelOpCount
+)
+
+var modelOpNames = []string{"play", "pause", "skip", "stop", "fire"}
+
+func modelControllerFactory(t testing.TB, timer pomoTimer.PomoTimerIface) *PomoController {
+	durationCfg := pomoSession.SessionStateDurationConfig{
+		PomoSessionWork:       25 * time.Minute,
+		PomoSessionShortBreak: 5 * time.Minute,
+		PomoSessionLongBreak:  15 * time.Minute,
+	}
+	controller, err := ControllerFactory(
+		PomoControllerSessionOpt(func() pomoSession.PomoSessionIface {
+			return &pomoSession.PomoSession{WorkSessionsBreak: 3}
+		}),
+		PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+			return timer
+		}),
+		PomoControllerDurationF(durationCfg.GetDurationFactory),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return controller
+}
+
+// Invariants on controller internals. Empty if none is broken.
+func checkControllerInvariants(c *PomoController, timerPending bool) []string {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	broken := []string{}
+	if c.pauseAt != nil && timerPending {
+		broken = append(broken, "paused with running timer")
+	}
+	if c.endOfState == nil && timerPending {
+		broken = append(broken, "stopped with running timer")
+	}
+	if c.endOfState == nil && c.pauseAt != nil {
+		broken = append(broken, "stopped and paused")
+	}
+	running := c.endOfState != nil && c.pauseAt == nil && !c.overtime
+	if running && !timerPending {
+		broken = append(broken, "running without timer")
+	}
+	return broken
+}
+
+// Apply ops to controller and model and compare after every one of them.
+func checkModel(t testing.TB, ops []byte) {
+	timer := &checkTimer{}
+	controller := modelControllerFactory(t, timer)
+	model := &controllerModel{nWork: 3}
+	now := time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC)
+
+	trace := []string{}
+	for _, b := range ops {
+		op := int(b) % modelOpCount
+		trace = append(trace, modelOpNames[op])
+		now = now.Add(time.Minute)
+
+		var err error
+		var expectOk bool
+		switch op {
+		case modelOpPlay:
+			err = controller.Play(now)
+			expectOk = model.play()
+		case modelOpPause:
+			err = controller.Pause(now)
+			expectOk = model.pause()
+		case modelOpSkip:
+			err = controller.Skip(now)
+			expectOk = model.skip()
+		case modelOpStop:
+			err = controller.Stop(now)
+			expectOk = model.stop()
+		case modelOpFire:
+			fired := timer.fire()
+			expectOk = model.fire()
+			if fired != expectOk {
+				t.Fatalf("%v: expected fire %v, got %v", trace, expectOk, fired)
+			}
+		}
+
+		if op != modelOpFire && (err == nil) != expectOk {
+			t.Fatalf("%v: expected success %v, got error %v", trace, expectOk, err)
+		}
+
+		status := controller.Status()
+		if status.State != model.state() {
+			t.Fatalf("%v: expected state %s, got %s", trace, model.state(), status.State)
+		}
+		if model.running && status.WorkedSessions != model.worked {
+			t.Fatalf("%v: expected %d worked sessions, got %d", trace, model.worked, status.WorkedSessions)
+		}
+		if broken := checkControllerInvariants(controller, timer.isPending()); len(broken) > 0 {
+			t.Fatalf("%v: broken invariants %v", trace, broken)
+		}
+		if len(timer.violations) > 0 {
+			t.Fatalf("%v: timer misuse %v", trace, timer.violations)
+		}
+	}
+}
+
+// =====
+// TESTS
+// =====
+
+func TestControllerModelRegressions(t *testing.T) {
+	for _, ops := range [][]byte{
+		// SKIP WHILE PAUSED.
+		{modelOpPlay, modelOpPause, modelOpSkip, modelOpFire},
+		// PAUSE ON STOPPED CONTROLLER.
+		{modelOpPause, modelOpPlay, modelOpFire},
+		{modelOpPlay, modelOpPause, modelOpStop, modelOpPause, modelOpPlay},
+	} {
+		checkModel(t, ops)
+	}
+}
+
+func TestControllerModelRandom(t *testing.T) {
+	for seed := range int64(200) {
+		rng := rand.New(rand.NewSource(seed))
+		ops := make([]byte, 60)
+		for i := range ops {
+			ops[i] = byte(rng.Intn(modelOpCount))
+		}
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			checkModel(t, ops)
+		})
+	}
+}
+
+func FuzzControllerModel(f *testing.F) {
+	f.Add([]byte{modelOpPlay, modelOpFire, modelOpFire, modelOpPause, modelOpSkip})
+	f.Add([]byte{modelOpPlay, modelOpPause, modelOpPlay, modelOpStop, modelOpPlay})
+	f.Add([]byte{modelOpPause, modelOpSkip, modelOpStop, modelOpPlay, modelOpFire})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		checkModel(t, ops)
+	})
+}
+
+// TIMER FIRING CONCURRENTLY WITH ACTIONS. THE CALLBACK IS TAKEN BEFORE THE
+// ACTION AND RUN AFTER IT, AS WHEN IT WAITS FOR THE CONTROLLER LOCK.
+func TestControllerStaleCallback(t *testing.T) {
+	actions := map[string]func(c *PomoController, now time.Time) error{
+		"pause": (*PomoController).Pause,
+		"skip":  (*PomoController).Skip,
+		"stop":  (*PomoController).Stop,
+	}
+
+	for name, action := range actions {
+		t.Run(name, func(t *testing.T) {
+			timer := &checkTimer{}
+			controller := modelControllerFactory(t, timer)
+			now := time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC)
+
+			if err := controller.Play(now); err != nil {
+				t.Fatal(err)
+			}
+			before := controller.Status()
+
+			cb := timer.take()
+			if err := action(controller, now.Add(time.Minute)); err != nil {
+				t.Fatalf("Expected %s to succeed with a callback in flight, got %v", name, err)
+			}
+			after := controller.Status()
+			cb()
+
+			if status := controller.Status(); status.State != after.State {
+				t.Fatalf("Expected stale callback to be dropped, %s went %s -> %s", before.State, after.State, status.State)
+			}
+			if broken := checkControllerInvariants(controller, timer.isPending()); len(broken) > 0 {
+				t.Fatalf("Broken invariants %v", broken)
+			}
+			if len(timer.violations) > 0 {
+				t.Fatalf("Timer misuse %v", timer.violations)
+			}
+		})
+	}
+}
+
+// REAL TIMER WITH SHORT STATES AND ACTIONS FROM SEVERAL GOROUTINES. RUN WITH
+// -race.
+func TestControllerConcurrent(t *testing.T) {
+	durationCfg := pomoSession.SessionStateDurationConfig{
+		PomoSessionWork:       time.Millisecond,
+		PomoSessionShortBreak: time.Millisecond,
+		PomoSessionLongBreak:  time.Millisecond,
+	}
+	controller, err := ControllerFactory(
+		PomoControllerSessionOpt(func() pomoSession.PomoSessionIface {
+			return &pomoSession.PomoSession{WorkSessionsBreak: 3}
+		}),
+		PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+			return new(pomoTimer.PomoTimer)
+		}),
+		PomoControllerDurationF(durationCfg.GetDurationFactory),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for worker := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(worker)))
+			for range 300 {
+				now := time.Now()
+				switch rng.Intn(4) {
+				case 0:
+					controller.Play(now)
+				case 1:
+					controller.Pause(now)
+				case 2:
+					controller.Skip(now)
+				case 3:
+					controller.Stop(now)
+				}
+				controller.Status()
+				time.Sleep(time.Duration(rng.Intn(200)) * time.Microsecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// EVERY ACTION KEEPS WORKING AFTERWARDS.
+	now := time.Now()
+	controller.Stop(now)
+	if err := controller.Play(now); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Pause(now); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Skip(now); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Stop(now); err != nil {
+		t.Fatal(err)
+	}
+}
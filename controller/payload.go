@@ -0,0 +1,272 @@
+// Json document written to the stdin of exec hooks.
+
+package controller
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Grows on breaking changes of the hook payload only. New fields may be added
+// without notice.
+const HookPayloadVersion = 1
+
+// Document written to hook stdin. Event is the same as POMO_EVENT. Data holds
+// one of the HookData structs below depending on the event. Durations are in
+// seconds so json output is easy to consume.
+type HookPayload struct {
+	Version int
+	Event   string
+	At      time.Time
+	Data    any
+}
+
+type HookDataPlay struct {
+	CurrentState                string
+	NextState                   string
+	CurrentStateDurationSeconds float64
+	WorkedSessions              int
+	Label                       SessionLabel
+}
+
+type HookDataStop struct {
+	CurrentState     string
+	TimeSpentSeconds float64
+	TimeLeftSeconds  float64
+	OvertimeSeconds  float64
+	WorkedSessions   int
+	Label            SessionLabel
+}
+
+type HookDataPause struct {
+	CurrentState     string
+	TimeSpentSeconds float64
+	TimeLeftSeconds  float64
+	Reason           string
+	WorkedSessions   int
+	Label            SessionLabel
+}
+
+type HookDataEndOfState struct {
+	CurrentState     string
+	NextState        string
+	TimeSpentSeconds float64
+	TimeLeftSeconds  float64
+	OvertimeSeconds  float64
+	Skipped          bool
+	CaughtUp         bool
+	Shortened        bool
+	WorkedSessions   int
+	Label            SessionLabel
+}
+
+type HookDataLabel struct {
+	CurrentState   string
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+type HookDataExtend struct {
+	CurrentState    string
+	DeltaSeconds    float64
+	TimeLeftSeconds float64
+	WorkedSessions  int
+	Label           SessionLabel
+}
+
+type HookDataOvertime struct {
+	CurrentState   string
+	NextState      string
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+type HookDataInterrupt struct {
+	CurrentState   string
+	Kind           string
+	Note           string
+	Interruptions  PomoControllerInterruptions
+	Voided         bool
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+type HookDataGoalReached struct {
+	Progress       PomoControllerGoalProgress
+	WorkedSessions int
+	Label          SessionLabel
+}
+
+type HookDataWarning struct {
+	CurrentState    string
+	NextState       string
+	TimeLeftSeconds float64
+	WorkedSessions  int
+	Label           SessionLabel
+}
+
+type HookDataError struct {
+	Message string
+}
+
+func (p HookPayload) Marshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// --------------------
+// PAYLOAD CONSTRUCTION
+// --------------------
+
+// Payload of a bus event. False if the event has no hook payload.
+func NewHookPayload(event PomoControllerEvent) (HookPayload, bool) {
+	switch payload := event.Payload.(type) {
+	case error:
+		return errorPayload(event.Timestamp, payload), true
+	case PomoControllerEventArgsPlay:
+		return playPayload(payload), true
+	case PomoControllerEventArgsStop:
+		return stopPayload(payload), true
+	case PomoControllerEventArgsPause:
+		return pausePayload(payload), true
+	case PomoControllerEventArgsNextState:
+		return endOfStatePayload(payload), true
+	case PomoControllerEventArgsLabel:
+		return labelPayload(payload), true
+	case PomoControllerEventArgsExtend:
+		return extendPayload(payload), true
+	case PomoControllerEventArgsOvertime:
+		return overtimePayload(payload), true
+	case PomoControllerEventArgsInterrupt:
+		return interruptPayload(payload), true
+	case PomoControllerEventArgsGoal:
+		return goalPayload(payload), true
+	case PomoControllerEventArgsWarning:
+		return warningPayload(payload), true
+	}
+	return HookPayload{}, false
+}
+
+func newHookPayload(event string, at time.Time, data any) HookPayload {
+	return HookPayload{
+		Version: HookPayloadVersion,
+		Event:   event,
+		At:      at,
+		Data:    data,
+	}
+}
+
+// Tags are always an array, never null.
+func hookLabel(label SessionLabel) SessionLabel {
+	if label.Tags == nil {
+		label.Tags = []string{}
+	}
+	return label
+}
+
+func playPayload(event PomoControllerEventArgsPlay) HookPayload {
+	return newHookPayload("Play", event.At, HookDataPlay{
+		CurrentState:                event.CurrentState.String(),
+		NextState:                   event.NextState.String(),
+		CurrentStateDurationSeconds: event.CurrentStateDuration.Seconds(),
+		WorkedSessions:              event.WorkedSessions,
+		Label:                       hookLabel(event.Label),
+	})
+}
+
+func stopPayload(event PomoControllerEventArgsStop) HookPayload {
+	return newHookPayload("Stop", event.At, HookDataStop{
+		CurrentState:     event.CurrentState.String(),
+		TimeSpentSeconds: event.TimeSpent.Seconds(),
+		TimeLeftSeconds:  event.TimeLeft.Seconds(),
+		OvertimeSeconds:  event.Overtime.Seconds(),
+		WorkedSessions:   event.WorkedSessions,
+		Label:            hookLabel(event.Label),
+	})
+}
+
+func pausePayload(event PomoControllerEventArgsPause) HookPayload {
+	return newHookPayload("Pause", event.At, HookDataPause{
+		CurrentState:     event.CurrentState.String(),
+		TimeSpentSeconds: event.TimeSpent.Seconds(),
+		TimeLeftSeconds:  event.TimeLeft.Seconds(),
+		Reason:           event.Reason,
+		WorkedSessions:   event.WorkedSessions,
+		Label:            hookLabel(event.Label),
+	})
+}
+
+func endOfStatePayload(event PomoControllerEventArgsNextState) HookPayload {
+	return newHookPayload("EndOfState", event.At, HookDataEndOfState{
+		CurrentState:     event.CurrentState.String(),
+		NextState:        event.NextState.String(),
+		TimeSpentSeconds: event.TimeSpent.Seconds(),
+		TimeLeftSeconds:  event.TimeLeft.Seconds(),
+		OvertimeSeconds:  event.Overtime.Seconds(),
+		Skipped:          event.Skipped,
+		CaughtUp:         event.CaughtUp,
+		Shortened:        event.Shortened,
+		WorkedSessions:   event.WorkedSessions,
+		Label:            hookLabel(event.Label),
+	})
+}
+
+func labelPayload(event PomoControllerEventArgsLabel) HookPayload {
+	return newHookPayload("Label", event.At, HookDataLabel{
+		CurrentState:   event.CurrentState.String(),
+		WorkedSessions: event.WorkedSessions,
+		Label:          hookLabel(event.Label),
+	})
+}
+
+func extendPayload(event PomoControllerEventArgsExtend) HookPayload {
+	return newHookPayload("Extend", event.At, HookDataExtend{
+		CurrentState:    event.CurrentState.String(),
+		DeltaSeconds:    event.Delta.Seconds(),
+		TimeLeftSeconds: event.TimeLeft.Seconds(),
+		WorkedSessions:  event.WorkedSessions,
+		Label:           hookLabel(event.Label),
+	})
+}
+
+func overtimePayload(event PomoControllerEventArgsOvertime) HookPayload {
+	return newHookPayload("Overtime", event.At, HookDataOvertime{
+		CurrentState:   event.CurrentState.String(),
+		NextState:      event.NextState.String(),
+		WorkedSessions: event.WorkedSessions,
+		Label:          hookLabel(event.Label),
+	})
+}
+
+func interruptPayload(event PomoControllerEventArgsInterrupt) HookPayload {
+	return newHookPayload("Interrupt", event.At, HookDataInterrupt{
+		CurrentState:   event.CurrentState.String(),
+		Kind:           event.Kind.String(),
+		Note:           event.Note,
+		Interruptions:  event.Interruptions,
+		Voided:         event.Voided,
+		WorkedSessions: event.WorkedSessions,
+		Label:          hookLabel(event.Label),
+	})
+}
+
+func goalPayload(event PomoControllerEventArgsGoal) HookPayload {
+	return newHookPayload("GoalReached", event.At, HookDataGoalReached{
+		Progress:       event.Progress,
+		WorkedSessions: event.WorkedSessions,
+		Label:          hookLabel(event.Label),
+	})
+}
+
+func warningPayload(event PomoControllerEventArgsWarning) HookPayload {
+	return newHookPayload("Warning", event.At, HookDataWarning{
+		CurrentState:    event.CurrentState.String(),
+		NextState:       event.NextState.String(),
+		TimeLeftSeconds: event.TimeLeft.Seconds(),
+		WorkedSessions:  event.WorkedSessions,
+		Label:           hookLabel(event.Label),
+	})
+}
+
+func errorPayload(at time.Time, err error) HookPayload {
+	return newHookPayload("Error", at, HookDataError{Message: err.Error()})
+}
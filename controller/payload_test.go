@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// =====
+// TESTS
+// =====
+
+// THE SCHEMA IS A CONTRACT WITH USER SCRIPTS. CHANGES HERE MUST BE ADDITIVE.
+func TestHookPayloadSchema(t *testing.T) {
+	at := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	label := SessionLabel{Task: "report", Project: "acme"}
+
+	cases := []struct {
+		payload  HookPayload
+		expected string
+	}{
+		{
+			playPayload(PomoControllerEventArgsPlay{
+				At:                   at,
+				CurrentState:         PomoControllerWork,
+				NextState:            PomoControllerShortBreak,
+				CurrentStateDuration: 25 * time.Minute,
+				WorkedSessions:       2,
+				Label:                label,
+			}),
+			`{"Version":1,"Event":"Play","At":"2024-12-06T09:00:00Z","Data":{` +
+				`"CurrentState":"Work","NextState":"ShortBreak",` +
+				`"CurrentStateDurationSeconds":1500,"WorkedSessions":2,` +
+				`"Label":{"Task":"report","Project":"acme","Tags":[]}}}`,
+		},
+		{
+			endOfStatePayload(PomoControllerEventArgsNextState{
+				At:           at,
+				CurrentState: PomoControllerWork,
+				NextState:    PomoControllerLongBreak,
+				TimeSpent:    20 * time.Minute,
+				TimeLeft:     5 * time.Minute,
+				Skipped:      true,
+				Label:        SessionLabel{Tags: []string{"deep"}},
+			}),
+			`{"Version":1,"Event":"EndOfState","At":"2024-12-06T09:00:00Z","Data":{` +
+				`"CurrentState":"Work","NextState":"LongBreak",` +
+				`"TimeSpentSeconds":1200,"TimeLeftSeconds":300,"OvertimeSeconds":0,` +
+				`"Skipped":true,"CaughtUp":false,"Shortened":false,"WorkedSessions":0,"Label":{"Task":"","Project":"","Tags":["deep"]}}}`,
+		},
+		{
+			interruptPayload(PomoControllerEventArgsInterrupt{
+				At:            at,
+				CurrentState:  PomoControllerWork,
+				Kind:          PomoInterruptionExternal,
+				Note:          "phone",
+				Interruptions: PomoControllerInterruptions{External: 1},
+			}),
+			`{"Version":1,"Event":"Interrupt","At":"2024-12-06T09:00:00Z","Data":{` +
+				`"CurrentState":"Work","Kind":"External","Note":"phone",` +
+				`"Interruptions":{"Internal":0,"External":1},"Voided":false,` +
+				`"WorkedSessions":0,"Label":{"Task":"","Project":"","Tags":[]}}}`,
+		},
+		{
+			errorPayload(at, ErrStoppedTimer),
+			`{"Version":1,"Event":"Error","At":"2024-12-06T09:00:00Z","Data":{` +
+				`"Message":"` + ErrStoppedTimer.Error() + `"}}`,
+		},
+	}
+
+	for _, c := range cases {
+		b, err := c.payload.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != c.expected {
+			t.Fatalf("Unexpected %s payload\n%s\nexpected\n%s", c.payload.Event, b, c.expected)
+		}
+	}
+}
+
+func TestHookPayloadStdin(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "payload.json")
+	script := filepath.Join(dir, "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > "+out+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	WarningExecHookCtx(context.Background(), script)(PomoControllerEventArgsWarning{
+		At:           at,
+		CurrentState: PomoControllerWork,
+		NextState:    PomoControllerShortBreak,
+		TimeLeft:     2 * time.Minute,
+	})
+	execHookRunner.Wait()
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Version int
+		Event   string
+		At      time.Time
+		Data    HookDataWarning
+	}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.Version != HookPayloadVersion || payload.Event != "Warning" || !payload.At.Equal(at) {
+		t.Fatalf("Unexpected payload header %+v", payload)
+	}
+	if payload.Data.NextState != "ShortBreak" || payload.Data.TimeLeftSeconds != 120 {
+		t.Fatalf("Unexpected payload data %+v", payload.Data)
+	}
+}
@@ -0,0 +1,479 @@
+// Save and restore controller state so a server restart does not lose the
+// running session.
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+)
+
+// Maximum number of states caught up on restore. Past it the session is
+// considered abandoned and the controller is left stopped.
+const restoreMaxCatchUp = 100
+
+type PomoControllerSnapshot struct {
+	SavedAt       time.Time
+	EndOfState    *time.Time
+	PauseAt       *time.Time
+	Overtime      bool
+	StateDuration time.Duration
+	Label         SessionLabel
+	Interruptions PomoControllerInterruptions
+	Goal          *PomoControllerGoalProgress
+	Session       pomoSession.SessionSnapshot
+	// Time worked in the last work interval. Breaks may depend on it.
+	Worked time.Duration
+}
+
+// ----------------
+// CONTROLLER STATE
+// ----------------
+
+// Not thread safe. Must be called with the lock taken.
+func (c *PomoController) snapshot(now time.Time) (PomoControllerSnapshot, error) {
+	sess, ok := c.session.(pomoSession.PomoSessionSnapshotIface)
+	if !ok {
+		return PomoControllerSnapshot{}, ErrSnapshotUnsupported
+	}
+
+	var goal *PomoControllerGoalProgress
+	if c.goal != nil {
+		progress := c.goal.Progress(now)
+		goal = &progress
+	}
+
+	return PomoControllerSnapshot{
+		Goal:          goal,
+		SavedAt:       now,
+		EndOfState:    c.endOfState,
+		PauseAt:       c.pauseAt,
+		Overtime:      c.overtime,
+		StateDuration: c.stateDuration,
+		Label:         c.label,
+		Interruptions: c.interruptions,
+		Worked:        c.worked,
+		Session:       sess.Snapshot(),
+	}, nil
+}
+
+// Optional snapshot event wrapper. Run after every transition. The snapshot is
+// only sent once the action is done, see unlock.
+func (c *PomoController) snapshotEvent(now time.Time) {
+	if c.snapshotSink == nil {
+		return
+	}
+	c.snapshotAt = &now
+}
+
+// Send the state if the action changed it and release the lock. Every action
+// sends one snapshot at most.
+func (c *PomoController) unlock() {
+	defer c.locker.Unlock()
+
+	if c.snapshotAt == nil {
+		return
+	}
+	now := *c.snapshotAt
+	c.snapshotAt = nil
+
+	snapshot, err := c.snapshot(now)
+	if err != nil {
+		c.errorEvent(err)
+		return
+	}
+	c.snapshotSink(snapshot)
+}
+
+// Return a copy of the current state.
+func (c *PomoController) Snapshot(now time.Time) (PomoControllerSnapshot, error) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.snapshot(now)
+}
+
+// Set state from snapshot on a stopped controller. Paused and overtime
+// snapshots are restored as they are. Running ones depend on the policy: catch
+// up every state that should have ended by now (up to the first manual
+// transition) or pause on what's left of the state. Snapshots too old to
+// catch up leave the controller stopped.
+func (c *PomoController) Restore(
+	now time.Time,
+	snapshot PomoControllerSnapshot,
+	policy PomoControllerRestorePolicy,
+) error {
+	c.locker.Lock()
+	defer c.unlock()
+
+	if c.endOfState != nil {
+		c.errorEvent(ErrRunningTimer)
+		return ErrRunningTimer
+	}
+
+	sess, ok := c.session.(pomoSession.PomoSessionSnapshotIface)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	if err := sess.Restore(snapshot.Session); err != nil {
+		return err
+	}
+
+	// BEFORE CATCH UP ASKS FOR BREAK DURATIONS.
+	c.setWorked(snapshot.Worked)
+
+	// SESSIONS COMPLETED BEFORE ARE REAL, EVEN IF THE SESSION IS TOO OLD.
+	if c.goal != nil && snapshot.Goal != nil {
+		c.goal.Restore(snapshot.SavedAt, *snapshot.Goal)
+	}
+
+	if snapshot.EndOfState == nil {
+		c.label = snapshot.Label
+		return nil
+	}
+
+	eos := *snapshot.EndOfState
+
+	// WORKED OUT BEFORE CHANGING ANYTHING SO AN EXPIRED SESSION LEAVES NO
+	// TRACE.
+	catchingUp := snapshot.PauseAt == nil &&
+		!snapshot.Overtime &&
+		!c.isOpen(c.session.Status()) &&
+		policy == PomoControllerRestoreCatchUp
+	var plan catchUpPlan
+	if catchingUp {
+		var err error
+		plan, err = c.planCatchUp(now, eos, snapshot.StateDuration, snapshot.Label)
+		if err != nil {
+			c.session.Reset()
+			c.snapshotEvent(now)
+			return err
+		}
+	}
+
+	c.label = snapshot.Label
+	c.stateDuration = snapshot.StateDuration
+	c.interruptions = snapshot.Interruptions
+
+	if snapshot.PauseAt != nil {
+		pauseAt := *snapshot.PauseAt
+		c.pauseAt = &pauseAt
+		c.endOfState = &eos
+		return nil
+	}
+
+	// OVERTIME WORK WAS COUNTED WHEN IT BEGAN. RESTORED GOAL HAS IT.
+	if snapshot.Overtime {
+		c.overtime = true
+		c.goalCounted = true
+		c.endOfState = &eos
+		return nil
+	}
+
+	// OPEN ENDED WORK NEVER ENDS ON ITS OWN. NOTHING TO CATCH UP.
+	if c.isOpen(c.session.Status()) {
+		c.endOfState = &eos
+		if policy == PomoControllerRestorePause {
+			c.pauseAt = &now
+			c.pauseEvent(now)
+		} else {
+			c.playEvent(now)
+		}
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	if !catchingUp {
+		pauseAt := now
+		if pauseAt.After(eos) {
+			pauseAt = eos
+		}
+		c.pauseAt = &pauseAt
+		c.endOfState = &eos
+		c.pauseEvent(pauseAt)
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	c.endOfState = &eos
+	if err := c.applyCatchUp(plan); err != nil {
+		c.endOfState = nil
+		c.errorEvent(err)
+		return err
+	}
+
+	if c.overtime {
+		c.snapshotEvent(now)
+		return nil
+	}
+
+	if !c.openEnded() {
+		if err := c.waitEndOfState(now, *c.endOfState); err != nil {
+			c.endOfState = nil
+			c.errorEvent(err)
+			return err
+		}
+	}
+	c.playEvent(now)
+	c.snapshotEvent(now)
+	return nil
+}
+
+// --------
+// CATCH UP
+// --------
+
+// States that ended while nobody was watching, like while the server was down
+// or the system suspended. Worked out on the session and rewound so nothing
+// changes until it's applied.
+type catchUpPlan struct {
+	// End of states caught up, in order.
+	events        []PomoControllerEventArgsNextState
+	endOfState    time.Time
+	stateDuration time.Duration
+	overtime      bool
+	session       pomoSession.SessionSnapshot
+	// Time worked in the last work caught up. Zero if none.
+	worked time.Duration
+}
+
+// Work out every state that ended before now as if the controller had been
+// running all along, from the state ending at eos. Stops in overtime if the
+// advance is manual and at open ended work. Past restoreMaxCatchUp states the
+// session is considered abandoned.
+func (c *PomoController) planCatchUp(
+	now, eos time.Time,
+	duration time.Duration,
+	label SessionLabel,
+) (catchUpPlan, error) {
+	sess, ok := c.session.(pomoSession.PomoSessionSnapshotIface)
+	if !ok {
+		return catchUpPlan{}, ErrSnapshotUnsupported
+	}
+
+	// DURATIONS MAY DEPEND ON THE SESSION AND THE TIME WORKED SO THEY ARE
+	// MOVED FOR REAL. REWOUND EITHER WAY.
+	start := sess.Snapshot()
+	defer sess.Restore(start)
+	worked := c.worked
+	defer c.setWorked(worked)
+
+	plan := catchUpPlan{}
+	for i := 0; !eos.After(now); i++ {
+		if i >= restoreMaxCatchUp {
+			return catchUpPlan{}, ErrRestoreExpired
+		}
+		if c.advancePolicy.IsManual(c.session.Status()) {
+			plan.overtime = true
+			break
+		}
+
+		nextStatus := c.session.GetNextStatus()
+		plan.events = append(plan.events, PomoControllerEventArgsNextState{
+			At:             eos,
+			CurrentState:   SessionToControllerState(c.session.Status()),
+			NextState:      SessionToControllerState(nextStatus),
+			TimeSpent:      duration,
+			WorkedSessions: c.session.CompletedWorkSessions(),
+			Label:          label,
+			CaughtUp:       true,
+		})
+
+		if c.session.Status() == pomoSession.PomoSessionWork {
+			c.setWorked(duration)
+			plan.worked = duration
+		}
+		duration = c.durationFactory(nextStatus)
+		c.session.SetNextStatus(nextStatus)
+		eos = eos.Add(duration)
+
+		// OPEN ENDED WORK STARTED AT THE END OF THE BREAK. IT NEVER ENDS.
+		if c.isOpen(nextStatus) {
+			break
+		}
+	}
+
+	plan.endOfState = eos
+	plan.stateDuration = duration
+	plan.session = sess.Snapshot()
+	return plan, nil
+}
+
+// Move the controller to the planned state and report the caught up end of
+// states. They don't count for the goal. Call with lock.
+func (c *PomoController) applyCatchUp(plan catchUpPlan) error {
+	sess, ok := c.session.(pomoSession.PomoSessionSnapshotIface)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	if err := sess.Restore(plan.session); err != nil {
+		return err
+	}
+
+	for _, event := range plan.events {
+		c.caughtUpEvent(event)
+	}
+
+	if plan.worked > 0 {
+		c.setWorked(plan.worked)
+	}
+
+	eos := plan.endOfState
+	c.endOfState = &eos
+	c.stateDuration = plan.stateDuration
+	if len(plan.events) > 0 {
+		c.interruptions = PomoControllerInterruptions{}
+	}
+
+	if plan.overtime {
+		c.overtime = true
+		c.overtimeEvent(eos)
+	}
+	return nil
+}
+
+// Move through every state that ended before now. Nothing changes if there
+// are too many to catch up.
+func (c *PomoController) catchUp(now time.Time) error {
+	plan, err := c.planCatchUp(now, *c.endOfState, c.stateDuration, c.label)
+	if err != nil {
+		return err
+	}
+	return c.applyCatchUp(plan)
+}
+
+// ----------
+// STATE FILE
+// ----------
+
+// Default state file location following XDG base directory spec.
+func DefaultSnapshotPath() (string, error) {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		stateDir = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateDir, "pomogo", "state.json"), nil
+}
+
+// Write snapshot to path atomically so a crash never leaves half a file.
+func SaveSnapshotFile(path string, snapshot PomoControllerSnapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(&snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Saves snapshots to Path on its own goroutine so a slow disk never holds the
+// controller. Only the latest snapshot is kept while one is being written.
+type SnapshotFileWriter struct {
+	Path string
+	// Called with every save failure. Ignored if nil.
+	OnError func(err error)
+
+	// Latest snapshot not written yet. Nil if there is none.
+	pending *PomoControllerSnapshot
+	running bool
+	idle    *sync.Cond
+
+	locker sync.Mutex
+}
+
+// Queue snapshot replacing any other not written yet. Never blocks. Meant as
+// controller snapshot sink.
+func (w *SnapshotFileWriter) Save(snapshot PomoControllerSnapshot) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	w.init()
+
+	w.pending = &snapshot
+	if w.running {
+		return
+	}
+	w.running = true
+	go w.write()
+}
+
+// Wait until the latest snapshot queued so far is written.
+func (w *SnapshotFileWriter) Wait() {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	w.init()
+	for w.running {
+		w.idle.Wait()
+	}
+}
+
+// Call with lock.
+func (w *SnapshotFileWriter) init() {
+	if w.idle == nil {
+		w.idle = sync.NewCond(&w.locker)
+	}
+}
+
+func (w *SnapshotFileWriter) write() {
+	for {
+		w.locker.Lock()
+		snapshot := w.pending
+		w.pending = nil
+		if snapshot == nil {
+			w.running = false
+			w.idle.Broadcast()
+			w.locker.Unlock()
+			return
+		}
+		w.locker.Unlock()
+
+		if err := SaveSnapshotFile(w.Path, *snapshot); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+	}
+}
+
+// Read snapshot from path. Returns nil snapshot if there is no file.
+func LoadSnapshotFile(path string) (*PomoControllerSnapshot, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot PomoControllerSnapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
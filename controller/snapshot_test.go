@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+)
+
+// ========
+// FIXTURES
+// ========
+
+var snapshotDurationFactory = pomoSession.DurationFactory(
+	25*time.Minute,
+	5*time.Minute,
+	15*time.Minute,
+)
+
+// Run a controller to the second work session and return the last snapshot.
+func runningSnapshot(t *testing.T, start time.Time) PomoControllerSnapshot {
+	var last PomoControllerSnapshot
+	timer := &pomoTimer.MockCbTimer{}
+	ctrl := fakeClockControllerFactory(
+		t,
+		pomoTimer.NewFakeClock(start),
+		timerOpt(timer),
+		PomoControllerOptionSnapshotSink(func(s PomoControllerSnapshot) {
+			last = s
+		}),
+	)
+
+	if err := ctrl.Play(start); err != nil {
+		t.Fatal(err)
+	}
+	// WORK -> SHORT BREAK -> WORK
+	for i := 0; i < 2; i++ {
+		if err := timer.ForceDone(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return last
+}
+
+// =====
+// TESTS
+// =====
+
+func TestSnapshotFileRoundTrip(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	snapshot := runningSnapshot(t, start)
+	path := filepath.Join(t.TempDir(), "pomogo", "state.json")
+
+	if loaded, err := LoadSnapshotFile(path); err != nil || loaded != nil {
+		t.Fatalf("Expected no snapshot and no error, got %v %v", loaded, err)
+	}
+
+	if err := SaveSnapshotFile(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSnapshotFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loaded.EndOfState.Equal(*snapshot.EndOfState) {
+		t.Fatalf("End of state is %s, expected %s", loaded.EndOfState, snapshot.EndOfState)
+	}
+	if loaded.Session != snapshot.Session {
+		t.Fatalf("Session is %+v, expected %+v", loaded.Session, snapshot.Session)
+	}
+}
+
+func TestRestoreCatchUp(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	snapshot := runningSnapshot(t, start)
+
+	// SECOND WORK ENDS AT 9:55. BREAK UNTIL 10:00. THIRD WORK UNTIL 10:25.
+	now := start.Add(62 * time.Minute)
+
+	nextStates := []PomoControllerState{}
+	caughtUp := 0
+	timer := &pomoTimer.MockCbTimer{}
+	ctrl := fakeClockControllerFactory(
+		t,
+		pomoTimer.NewFakeClock(start),
+		timerOpt(timer),
+		PomoControllerOptionNextStateSink(func(e PomoControllerEventArgsNextState) {
+			if e.CaughtUp {
+				caughtUp++
+			}
+			nextStates = append(nextStates, e.NextState)
+		}),
+		PomoControllerOptionDailyGoal(&DailyGoal{Target: 8, Location: time.UTC}),
+	)
+
+	if err := ctrl.Restore(now, snapshot, PomoControllerRestoreCatchUp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nextStates) != 2 || caughtUp != 2 {
+		t.Fatalf("Expected 2 caught up states, got %v (%d caught up)", nextStates, caughtUp)
+	}
+
+	st := ctrl.Status()
+	if st.State != PomoControllerWork {
+		t.Fatalf("Restored state is %s, expected Work", st.State)
+	}
+	if st.WorkedSessions != 2 {
+		t.Fatalf("Worked sessions is %d, expected 2", st.WorkedSessions)
+	}
+
+	expectedEos := start.Add(85 * time.Minute)
+	if !ctrl.endOfState.Equal(expectedEos) {
+		t.Fatalf("End of state is %s, expected %s", ctrl.endOfState, expectedEos)
+	}
+
+	// NOBODY SAW THE SECOND WORK SESSION END.
+	if done := ctrl.goal.Progress(now).Done; done != 0 {
+		t.Fatalf("Goal done is %d, expected caught up work not to count", done)
+	}
+
+	// TIMER MUST BE WAITING. THE STATE SEEN ENDING IS NOT CAUGHT UP.
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+	if len(nextStates) != 3 || caughtUp != 2 {
+		t.Fatalf("Expected an observed end of state, got %v (%d caught up)", nextStates, caughtUp)
+	}
+}
+
+func TestRestorePause(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	snapshot := runningSnapshot(t, start)
+	now := start.Add(40 * time.Minute)
+
+	timer := &pomoTimer.MockCbTimer{}
+	ctrl := fakeClockControllerFactory(t, pomoTimer.NewFakeClock(start), timerOpt(timer))
+
+	if err := ctrl.Restore(now, snapshot, PomoControllerRestorePause); err != nil {
+		t.Fatal(err)
+	}
+
+	st := ctrl.Status()
+	if st.State != PomoControllerPause {
+		t.Fatalf("Restored state is %s, expected Paused", st.State)
+	}
+
+	// 15 MINUTES LEFT OF SECOND WORK SESSION.
+	if err := ctrl.Play(now); err != nil {
+		t.Fatal(err)
+	}
+	if left := ctrl.endOfState.Sub(now); left != 15*time.Minute {
+		t.Fatalf("Time left is %s, expected 15m", left)
+	}
+}
+
+func TestRestoreExpired(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	snapshot := runningSnapshot(t, start)
+	now := start.Add(30 * 24 * time.Hour)
+
+	timer := &pomoTimer.MockCbTimer{}
+	var saved *PomoControllerSnapshot
+	ctrl := fakeClockControllerFactory(
+		t,
+		pomoTimer.NewFakeClock(start),
+		timerOpt(timer),
+		PomoControllerOptionListener(PomoControllerListener{
+			NextState: func(e PomoControllerEventArgsNextState) {
+				t.Fatalf("Unexpected end of state on expired restore %+v", e)
+			},
+			Goal: func(e PomoControllerEventArgsGoal) {
+				t.Fatalf("Unexpected goal event on expired restore %+v", e)
+			},
+		}),
+		PomoControllerOptionSnapshotSink(func(s PomoControllerSnapshot) {
+			saved = &s
+		}),
+	)
+
+	if err := ctrl.Restore(now, snapshot, PomoControllerRestoreCatchUp); err != ErrRestoreExpired {
+		t.Fatalf("Expected %v, got %v", ErrRestoreExpired, err)
+	}
+
+	if st := ctrl.Status().State; st != PomoControllerStopped {
+		t.Fatalf("Expired restore state is %s, expected Stopped", st)
+	}
+
+	// NOTHING OF THE ABANDONED SESSION IS KEPT.
+	if worked := ctrl.session.CompletedWorkSessions(); worked != 0 {
+		t.Fatalf("Worked sessions is %d, expected a fresh session", worked)
+	}
+	if saved == nil || saved.EndOfState != nil || saved.Session.WorkedSessions != 0 {
+		t.Fatalf("Expected stopped state to be saved, got %+v", saved)
+	}
+}
+
+// BREAKS DEPENDING ON THE TIME WORKED KNOW IT AFTER RESTORE AND CATCH UP.
+func TestRestoreWorked(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	snapshot := runningSnapshot(t, start)
+	if snapshot.Worked != 25*time.Minute {
+		t.Fatalf("Worked is %s, expected 25m", snapshot.Worked)
+	}
+
+	restore := func(
+		now time.Time,
+		snapshot PomoControllerSnapshot,
+		policy PomoControllerRestorePolicy,
+	) (*PomoController, *pomoSession.FlowtimeDuration) {
+		flow := &pomoSession.FlowtimeDuration{Base: snapshotDurationFactory, Ratio: 0.2}
+		ctrl := fakeClockControllerFactory(
+			t,
+			pomoTimer.NewFakeClock(now),
+			timerOpt(&pomoTimer.MockCbTimer{}),
+			PomoControllerDurationF(flow.GetDurationFactory),
+			PomoControllerOptionWorked(flow),
+		)
+		if err := ctrl.Restore(now, snapshot, policy); err != nil {
+			t.Fatal(err)
+		}
+		return ctrl, flow
+	}
+
+	// FROM THE SNAPSHOT.
+	snapshot.Worked = 40 * time.Minute
+	_, flow := restore(start.Add(40*time.Minute), snapshot, PomoControllerRestorePause)
+	if brk := flow.GetDurationFactory()(pomoSession.PomoSessionShortBreak); brk != 8*time.Minute {
+		t.Fatalf("Break is %s, expected 8m", brk)
+	}
+
+	// FROM THE WORK CAUGHT UP. SECOND WORK ENDS AT 9:55.
+	ctrl, flow := restore(start.Add(57*time.Minute), snapshot, PomoControllerRestoreCatchUp)
+	if brk := flow.GetDurationFactory()(pomoSession.PomoSessionShortBreak); brk != 5*time.Minute {
+		t.Fatalf("Break is %s, expected 5m", brk)
+	}
+	if expected := start.Add(60 * time.Minute); !ctrl.endOfState.Equal(expected) {
+		t.Fatalf("End of state is %s, expected %s", ctrl.endOfState, expected)
+	}
+}
+
+// SNAPSHOTS ARE SENT ONCE PER ACTION, NOT ONCE PER STEP OF IT.
+func TestSnapshotOncePerAction(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	saved := 0
+	timer := &pomoTimer.MockCbTimer{}
+	ctrl := fakeClockControllerFactory(
+		t,
+		pomoTimer.NewFakeClock(start),
+		timerOpt(timer),
+		PomoControllerOptionSnapshotSink(func(s PomoControllerSnapshot) {
+			saved++
+		}),
+	)
+
+	actions := []func() error{
+		func() error { return ctrl.Play(start) },
+		func() error { return ctrl.Pause(start.Add(time.Minute)) },
+		func() error { return ctrl.Play(start.Add(2 * time.Minute)) },
+		func() error { return ctrl.Skip(start.Add(3 * time.Minute)) },
+		timer.ForceDone,
+	}
+	for i, action := range actions {
+		if err := action(); err != nil {
+			t.Fatal(err)
+		}
+		if saved != i+1 {
+			t.Fatalf("Expected %d snapshots after action %d, got %d", i+1, i, saved)
+		}
+	}
+
+	ctrl.Status()
+	if saved != len(actions) {
+		t.Fatalf("Expected no snapshot on status, got %d", saved)
+	}
+}
+
+func TestSnapshotFileWriter(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "pomogo", "state.json")
+	writer := &SnapshotFileWriter{
+		Path: path,
+		OnError: func(err error) {
+			t.Errorf("Unexpected save error %v", err)
+		},
+	}
+
+	// NOTHING QUEUED.
+	writer.Wait()
+
+	for i := 0; i < 10; i++ {
+		writer.Save(PomoControllerSnapshot{SavedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+	writer.Wait()
+
+	loaded, err := LoadSnapshotFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded == nil || !loaded.SavedAt.Equal(start.Add(9*time.Minute)) {
+		t.Fatalf("Expected latest snapshot written, got %+v", loaded)
+	}
+}
@@ -0,0 +1,100 @@
+// Periodic progress reports so status bars don't have to poll.
+
+package controller
+
+import (
+	"context"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"time"
+)
+
+// Shortest interval between ticks.
+const MinTickInterval = 100 * time.Millisecond
+
+// Return progress of the current state at now.
+func (c *PomoController) Tick(now time.Time) PomoControllerTick {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	tick := PomoControllerTick{
+		At:    now,
+		State: PomoControllerStopped,
+		Label: c.statusLabel(),
+	}
+
+	if c.endOfState == nil {
+		return tick
+	}
+
+	// TIME LEFT IS FROZEN WHILE PAUSED.
+	from := now
+	if c.pauseAt != nil {
+		from = *c.pauseAt
+		tick.State = PomoControllerPause
+	} else if c.overtime {
+		tick.State = PomoControllerOvertime
+	} else {
+		tick.State = SessionToControllerState(c.session.Status())
+	}
+
+	timeLeft := max(c.endOfState.Sub(from), 0)
+	elapsed := c.stateDuration - timeLeft + c.overtimeAmount(now)
+	if c.openEnded() {
+		// KEEPS COUNTING UP PAST ITS MINIMUM.
+		elapsed = c.elapsed(from)
+	}
+
+	tick.TimeLeft = StatusDuration(timeLeft)
+	tick.Elapsed = StatusDuration(elapsed)
+	tick.Percent = 100
+	if timeLeft > 0 && c.stateDuration > 0 {
+		tick.Percent = 100 * float64(elapsed) / float64(c.stateDuration)
+	}
+	return tick
+}
+
+// Send a tick right away and every interval after that until the returned
+// function is called.
+func (c *PomoController) SubscribeTicks(
+	every time.Duration,
+	sink func(tick PomoControllerTick),
+) (func(), error) {
+	return c.SubscribeTicksCtx(context.Background(), every, sink)
+}
+
+// Same as SubscribeTicks. Ticks also end once ctx or the root context is done.
+func (c *PomoController) SubscribeTicksCtx(
+	ctx context.Context,
+	every time.Duration,
+	sink func(tick PomoControllerTick),
+) (func(), error) {
+	if every < MinTickInterval {
+		return nil, ErrInvalidTickInterval
+	}
+	if err := c.ctxErr(ctx); err != nil {
+		return nil, err
+	}
+
+	clock := pomoTimer.ClockOrReal(c.clock)
+	ticker := &pomoTimer.PomoTicker{Clock: clock}
+
+	sink(c.Tick(clock.Now()))
+
+	err := ticker.Start(every, func(now time.Time) {
+		sink(c.Tick(now))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ONLY FAILS IF ALREADY STOPPED.
+	unsub := func() { _ = ticker.Stop() }
+	stopCtx := context.AfterFunc(ctx, unsub)
+	stopRoot := context.AfterFunc(c.context(), unsub)
+
+	return func() {
+		stopCtx()
+		stopRoot()
+		unsub()
+	}, nil
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+var tickRefNow = time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC)
+
+// 20 MINUTES OF WORK.
+var tickDurations = PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+	return pomoSession.DurationFactory(20*time.Minute, 5*time.Minute, 15*time.Minute)
+})
+
+// =====
+// TESTS
+// =====
+
+func TestControllerTick(t *testing.T) {
+	clock := pomoTimer.NewFakeClock(tickRefNow)
+	controller := fakeClockControllerFactory(t, clock, tickDurations)
+
+	if tick := controller.Tick(clock.Now()); tick.State != PomoControllerStopped {
+		t.Fatalf("Expected stopped tick, got %s", tick.State)
+	}
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(5 * time.Minute)
+
+	tick := controller.Tick(clock.Now())
+	if tick.State != PomoControllerWork {
+		t.Fatalf("Expected work tick, got %s", tick.State)
+	}
+	if time.Duration(tick.TimeLeft) != 15*time.Minute {
+		t.Fatalf("Expected 15m left, got %s", time.Duration(tick.TimeLeft))
+	}
+	if time.Duration(tick.Elapsed) != 5*time.Minute {
+		t.Fatalf("Expected 5m elapsed, got %s", time.Duration(tick.Elapsed))
+	}
+	if tick.Percent != 25 {
+		t.Fatalf("Expected 25%%, got %f", tick.Percent)
+	}
+
+	// FROZEN WHILE PAUSED.
+	if err := controller.Pause(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	tick = controller.Tick(clock.Now())
+	if tick.State != PomoControllerPause || tick.Percent != 25 {
+		t.Fatalf("Expected paused at 25%%, got %s at %f", tick.State, tick.Percent)
+	}
+}
+
+func TestControllerSubscribeTicks(t *testing.T) {
+	clock := pomoTimer.NewFakeClock(tickRefNow)
+	controller := fakeClockControllerFactory(t, clock, tickDurations)
+
+	if _, err := controller.SubscribeTicks(time.Millisecond, func(PomoControllerTick) {}); err != ErrInvalidTickInterval {
+		t.Fatalf("Expected ErrInvalidTickInterval, got %v", err)
+	}
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	ticks := []PomoControllerTick{}
+	unsubscribe, err := controller.SubscribeTicks(time.Minute, func(tick PomoControllerTick) {
+		ticks = append(ticks, tick)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// FIRST TICK RIGHT AWAY.
+	if len(ticks) != 1 {
+		t.Fatalf("Expected initial tick, got %d", len(ticks))
+	}
+
+	clock.Advance(30 * time.Minute)
+	if len(ticks) != 31 {
+		t.Fatalf("Expected 31 ticks, got %d", len(ticks))
+	}
+	// 22 MINUTES IN.
+	if tick := ticks[22]; tick.State != PomoControllerShortBreak {
+		t.Fatalf("Expected short break, got %s", tick.State)
+	}
+
+	unsubscribe()
+	clock.Advance(30 * time.Minute)
+	if len(ticks) != 31 {
+		t.Fatalf("Expected no ticks after unsubscribe, got %d", len(ticks))
+	}
+}
@@ -0,0 +1,135 @@
+// Warnings some time before the end of a state. "2 minutes left" nudges.
+
+package controller
+
+import (
+	"fmt"
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"strings"
+	"time"
+)
+
+// Time left to warn at, by session status. Several offsets per status are
+// fine.
+type PomoControllerWarnings map[pomoSession.PomoSessionStatus][]time.Duration
+
+// Largest offset still ahead of now for a state ending at then. That's the
+// next one to fire. False if none.
+func (w PomoControllerWarnings) next(
+	status pomoSession.PomoSessionStatus,
+	now, then time.Time,
+) (time.Duration, bool) {
+	var next time.Duration
+	found := false
+	for _, offset := range w[status] {
+		if then.Add(-offset).After(now) && offset > next {
+			next = offset
+			found = true
+		}
+	}
+	return next, found
+}
+
+// Parse comma separated status and offset pairs, same names as sequences:
+//
+//	work:5m,work:2m,short:1m,long:1m
+func ParseWarnings(text string) (PomoControllerWarnings, error) {
+	warnings := PomoControllerWarnings{}
+
+	for _, token := range strings.Split(text, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		name, offsetText, ok := strings.Cut(token, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: missing offset %q", ErrInvalidWarning, token)
+		}
+		status, err := pomoSession.ParseSessionStatus(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidWarning, err)
+		}
+		offset, err := time.ParseDuration(strings.TrimSpace(offsetText))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidWarning, err)
+		}
+		if offset <= 0 {
+			return nil, fmt.Errorf("%w: non positive offset %q", ErrInvalidWarning, token)
+		}
+		warnings[status] = append(warnings[status], offset)
+	}
+
+	return warnings, nil
+}
+
+// -----------------
+// CONTROLLER LOGIC
+// -----------------
+
+// Warning timer runs alongside the end of state one. Created on first use.
+func (c *PomoController) getWarningTimer() pomoTimer.PomoTimerIface {
+	if c.warningTimer == nil {
+		c.warningTimer = &pomoTimer.PomoTimer{Clock: c.clock}
+	}
+	return c.warningTimer
+}
+
+// Wait for the next warning of the current state, ending at then. Replaces any
+// pending one. Call with lock.
+func (c *PomoController) waitWarning(now, then time.Time) {
+	c.cancelWarning()
+
+	offset, ok := c.warnings.next(c.session.Status(), now, then)
+	if !ok {
+		return
+	}
+
+	at := then.Add(-offset)
+	cb := func() {
+		c.warn(at, then)
+	}
+	if err := c.getWarningTimer().WaitCb(at.Sub(now), cb); err != nil {
+		c.errorEvent(err)
+	}
+}
+
+// Drop pending warning if any. Call with lock.
+func (c *PomoController) cancelWarning() {
+	if c.warningTimer == nil {
+		return
+	}
+	// ONLY FAILS IF NOTHING IS PENDING.
+	_ = c.warningTimer.Cancel()
+}
+
+func (c *PomoController) warn(at, then time.Time) {
+	c.locker.Lock()
+	defer c.unlock()
+
+	// STALE WARNING. THE STATE CHANGED SINCE IT WAS SCHEDULED.
+	if c.pauseAt != nil || c.overtime || c.endOfState == nil || !c.endOfState.Equal(then) {
+		return
+	}
+
+	c.warningEvent(at, then.Sub(at))
+	c.waitWarning(at, then)
+}
+
+func (c *PomoController) warningEvent(now time.Time, timeLeft time.Duration) {
+	if !c.events.HasSubscribers() {
+		return
+	}
+
+	warningEvent := PomoControllerEventArgsWarning{
+		At:             now,
+		CurrentState:   SessionToControllerState(c.session.Status()),
+		NextState:      SessionToControllerState(c.session.GetNextStatus()),
+		TimeLeft:       timeLeft,
+		WorkedSessions: c.session.CompletedWorkSessions(),
+		Label:          c.label,
+	}
+
+	c.events.Publish(PomoControllerEventTypeWarning, now, warningEvent)
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+// 25/5/15 minutes. Warnings 5 and 2 minutes before end of work and 1 minute
+// before end of short break.
+func warningControllerFactory(
+	t *testing.T,
+	warnings *[]PomoControllerEventArgsWarning,
+) (*PomoController, *pomoTimer.FakeClock) {
+	offsets, err := ParseWarnings("work:2m,work:5m,short:1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := pomoTimer.NewFakeClock(time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC))
+	controller := fakeClockControllerFactory(
+		t,
+		clock,
+		PomoControllerOptionWarnings(offsets),
+		PomoControllerOptionWarningSink(func(event PomoControllerEventArgsWarning) {
+			*warnings = append(*warnings, event)
+		}),
+	)
+	return controller, clock
+}
+
+// =====
+// TESTS
+// =====
+
+func TestParseWarnings(t *testing.T) {
+	warnings, err := ParseWarnings("work:2m, w:5m,short:1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings[pomoSession.PomoSessionWork]) != 2 {
+		t.Fatalf("Expected two work warnings, got %v", warnings)
+	}
+	if len(warnings[pomoSession.PomoSessionLongBreak]) != 0 {
+		t.Fatalf("Expected no long break warnings, got %v", warnings)
+	}
+
+	for _, text := range []string{"work", "nap:2m", "work:soon", "work:-2m"} {
+		if _, err := ParseWarnings(text); err == nil {
+			t.Fatalf("Expected error parsing %q", text)
+		}
+	}
+}
+
+func TestControllerWarnings(t *testing.T) {
+	warnings := []PomoControllerEventArgsWarning{}
+	controller, clock := warningControllerFactory(t, &warnings)
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(30 * time.Minute)
+
+	expected := []struct {
+		state    PomoControllerState
+		timeLeft time.Duration
+		at       time.Duration
+	}{
+		{PomoControllerWork, 5 * time.Minute, 20 * time.Minute},
+		{PomoControllerWork, 2 * time.Minute, 23 * time.Minute},
+		{PomoControllerShortBreak, time.Minute, 29 * time.Minute},
+	}
+
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings, got %d", len(expected), len(warnings))
+	}
+	start := time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC)
+	for i, exp := range expected {
+		w := warnings[i]
+		if w.CurrentState != exp.state || w.TimeLeft != exp.timeLeft || !w.At.Equal(start.Add(exp.at)) {
+			t.Fatalf("Unexpected warning %d: %+v", i, w)
+		}
+	}
+}
+
+// PAUSE, SKIP AND STOP DROP PENDING WARNINGS.
+func TestControllerWarningsCancel(t *testing.T) {
+	warnings := []PomoControllerEventArgsWarning{}
+	controller, clock := warningControllerFactory(t, &warnings)
+
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(19 * time.Minute)
+	if err := controller.Pause(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if len(warnings) != 0 {
+		t.Fatalf("Expected no warnings while paused, got %d", len(warnings))
+	}
+
+	// 6 MINUTES LEFT ON RESUME.
+	if err := controller.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+	if len(warnings) != 1 || warnings[0].TimeLeft != 5*time.Minute {
+		t.Fatalf("Expected 5 minutes warning after resume, got %+v", warnings)
+	}
+
+	// SKIP TO SHORT BREAK. NO 2 MINUTES WARNING OF WORK.
+	if err := controller.Skip(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(3 * time.Minute)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected no warnings after skip, got %+v", warnings)
+	}
+
+	if err := controller.Stop(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected no warnings after stop, got %+v", warnings)
+	}
+	if clock.Waiters() != 0 {
+		t.Fatalf("Expected no pending timers after stop, got %d", clock.Waiters())
+	}
+}
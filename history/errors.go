@@ -0,0 +1,6 @@
+package history
+
+import "errors"
+
+var ErrCorruptJournal = errors.New("cannot parse history journal line")
+var ErrInvalidReport = errors.New("invalid report")
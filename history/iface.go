@@ -0,0 +1,31 @@
+// Interfaces and data structures of the interval history.
+
+package history
+
+import (
+	"time"
+
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+)
+
+// Record of a single interval as it happened.
+// TimeSpent excludes the time the interval was paused and includes overtime.
+type Interval struct {
+	State         pomoController.PomoControllerState
+	Start         time.Time
+	End           time.Time
+	TimeSpent     time.Duration
+	PausedTime    time.Duration
+	Overtime      time.Duration
+	Skipped       bool
+	Stopped       bool
+	Voided        bool
+	Interruptions int
+	Label         pomoController.SessionLabel
+}
+
+// Append only storage of intervals.
+type JournalIface interface {
+	Append(interval Interval) error
+	Read() ([]Interval, error)
+}
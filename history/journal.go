@@ -0,0 +1,94 @@
+// Append only journal stored as one json document per line.
+
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Default journal location following XDG base directory spec.
+func DefaultJournalPath() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataDir, "pomogo", "history.jsonl"), nil
+}
+
+// Journal backed by a json lines file. The file is opened on every append so
+// nothing is lost if the process dies between intervals.
+type FileJournal struct {
+	Path   string
+	locker sync.Mutex
+}
+
+func (j *FileJournal) Append(interval Interval) error {
+	j.locker.Lock()
+	defer j.locker.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(j.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// POINTER TO USE MARSHALJSON OF STATE.
+	if err := json.NewEncoder(f).Encode(&interval); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read every interval. A missing file is an empty history.
+func (j *FileJournal) Read() ([]Interval, error) {
+	j.locker.Lock()
+	defer j.locker.Unlock()
+
+	f, err := os.Open(j.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Interval{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	intervals := []Interval{}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var interval Interval
+		if err := json.Unmarshal(scanner.Bytes(), &interval); err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrCorruptJournal, line, err)
+		}
+		intervals = append(intervals, interval)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return intervals, nil
+}
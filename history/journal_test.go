@@ -0,0 +1,66 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+)
+
+func TestJournalRoundTrip(t *testing.T) {
+	journal := FileJournal{
+		Path: filepath.Join(t.TempDir(), "pomogo", "history.jsonl"),
+	}
+
+	// MISSING FILE IS EMPTY HISTORY.
+	intervals, err := journal.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 0 {
+		t.Fatalf("Expected empty history, got %d intervals", len(intervals))
+	}
+
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	expected := []Interval{
+		{
+			State:     pomoController.PomoControllerWork,
+			Start:     start,
+			End:       start.Add(25 * time.Minute),
+			TimeSpent: 25 * time.Minute,
+			Label:     pomoController.SessionLabel{Task: "report", Tags: []string{"docs"}},
+		},
+		{
+			State:     pomoController.PomoControllerShortBreak,
+			Start:     start.Add(25 * time.Minute),
+			End:       start.Add(27 * time.Minute),
+			TimeSpent: 2 * time.Minute,
+			Skipped:   true,
+		},
+	}
+
+	for _, interval := range expected {
+		if err := journal.Append(interval); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	intervals, err = journal.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(intervals) != len(expected) {
+		t.Fatalf("Expected %d intervals, got %d", len(expected), len(intervals))
+	}
+
+	for i := range expected {
+		got, exp := intervals[i], expected[i]
+		if got.State != exp.State || !got.Start.Equal(exp.Start) ||
+			got.TimeSpent != exp.TimeSpent || got.Skipped != exp.Skipped ||
+			got.Label.Task != exp.Label.Task {
+			t.Fatalf("Interval %d is %+v, expected %+v", i, got, exp)
+		}
+	}
+}
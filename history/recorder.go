@@ -0,0 +1,180 @@
+// Builds intervals out of controller events and stores them on a journal.
+
+package history
+
+import (
+	"sync"
+	"time"
+
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+)
+
+// Keeps track of the running interval. Closed intervals are appended to the
+// journal. Append errors are sent to OnError if set.
+type Recorder struct {
+	Journal JournalIface
+	OnError func(err error)
+
+	current  *Interval
+	pausedAt *time.Time
+	// Running interval reached its end and is in overtime.
+	ended  bool
+	locker sync.Mutex
+}
+
+func (r *Recorder) open(
+	at time.Time,
+	state pomoController.PomoControllerState,
+	label pomoController.SessionLabel,
+) {
+	r.current = &Interval{
+		State: state,
+		Start: at,
+		Label: label,
+	}
+	r.pausedAt = nil
+	r.ended = false
+}
+
+// How an interval ended.
+type intervalEnd struct {
+	skipped  bool
+	stopped  bool
+	voided   bool
+	overtime time.Duration
+}
+
+// Finish current interval and append it to the journal.
+func (r *Recorder) close(at time.Time, end intervalEnd) {
+	if r.current == nil {
+		return
+	}
+
+	interval := *r.current
+	if r.pausedAt != nil {
+		interval.PausedTime += at.Sub(*r.pausedAt)
+	}
+	interval.End = at
+	interval.TimeSpent = at.Sub(interval.Start) - interval.PausedTime
+	interval.Skipped = end.skipped
+	interval.Stopped = end.stopped
+	interval.Voided = end.voided
+	interval.Overtime = end.overtime
+
+	r.current = nil
+	r.pausedAt = nil
+	r.ended = false
+
+	if err := r.Journal.Append(interval); err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// Start or resume interval.
+func (r *Recorder) OnPlay(event pomoController.PomoControllerEventArgsPlay) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if r.current == nil {
+		r.open(event.At, event.CurrentState, event.Label)
+		return
+	}
+
+	if r.pausedAt != nil {
+		r.current.PausedTime += event.At.Sub(*r.pausedAt)
+		r.pausedAt = nil
+	}
+}
+
+func (r *Recorder) OnPause(event pomoController.PomoControllerEventArgsPause) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if r.current == nil || r.pausedAt != nil {
+		return
+	}
+	at := event.At
+	r.pausedAt = &at
+}
+
+// End of state by timer or skip. Closes the interval and opens the next one.
+// Caught up ones were never seen: the interval is dropped and nothing is
+// recorded until play.
+func (r *Recorder) OnNextState(event pomoController.PomoControllerEventArgsNextState) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if event.CaughtUp {
+		r.current = nil
+		r.pausedAt = nil
+		return
+	}
+
+	r.close(event.At, intervalEnd{skipped: event.Skipped, overtime: event.Overtime})
+	r.open(event.At, event.NextState, event.Label)
+}
+
+// The running interval is complete even if stopped before play moves on.
+func (r *Recorder) OnOvertime(event pomoController.PomoControllerEventArgsOvertime) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if r.current == nil {
+		return
+	}
+	r.ended = true
+}
+
+// Stop in overtime closes a complete interval. Otherwise it is cut short.
+func (r *Recorder) OnStop(event pomoController.PomoControllerEventArgsStop) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	r.close(event.At, intervalEnd{stopped: !r.ended, overtime: event.Overtime})
+}
+
+// Count interruptions. A voided interval is closed and started over, still
+// paused if it was.
+func (r *Recorder) OnInterrupt(event pomoController.PomoControllerEventArgsInterrupt) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if r.current == nil {
+		return
+	}
+
+	r.current.Interruptions++
+	if event.Voided {
+		paused := r.pausedAt != nil
+		r.close(event.At, intervalEnd{voided: true})
+		r.open(event.At, event.CurrentState, event.Label)
+		if paused {
+			at := event.At
+			r.pausedAt = &at
+		}
+	}
+}
+
+// Label changes apply to the running interval.
+func (r *Recorder) OnLabel(event pomoController.PomoControllerEventArgsLabel) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if r.current == nil {
+		return
+	}
+	r.current.Label = event.Label
+}
+
+// Listener to be set as controller option.
+func (r *Recorder) Listener() pomoController.PomoControllerListener {
+	return pomoController.PomoControllerListener{
+		Play:      r.OnPlay,
+		Pause:     r.OnPause,
+		NextState: r.OnNextState,
+		Stop:      r.OnStop,
+		Overtime:  r.OnOvertime,
+		Label:     r.OnLabel,
+		Interrupt: r.OnInterrupt,
+	}
+}
@@ -0,0 +1,205 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+)
+
+// ========
+// FIXTURES
+// ========
+
+type memoryJournal struct {
+	intervals []Interval
+}
+
+func (j *memoryJournal) Append(interval Interval) error {
+	j.intervals = append(j.intervals, interval)
+	return nil
+}
+
+func (j *memoryJournal) Read() ([]Interval, error) {
+	return j.intervals, nil
+}
+
+func recorderControllerFactory(
+	t *testing.T,
+	timer pomoTimer.PomoTimerIface,
+	recorder *Recorder,
+	options ...pomoController.PomoControllerOption,
+) *pomoController.PomoController {
+	fixedOptions := []pomoController.PomoControllerOption{
+		pomoController.PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+			return timer
+		}),
+		pomoController.PomoControllerOptionListener(recorder.Listener()),
+	}
+	ctrl, err := pomoController.FakeClockControllerFactory(
+		pomoTimer.NewFakeClock(time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)),
+		append(fixedOptions, options...)...,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctrl
+}
+
+// =====
+// TESTS
+// =====
+
+func TestRecorderIntervals(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	journal := &memoryJournal{}
+	recorder := &Recorder{Journal: journal}
+	timer := &pomoTimer.MockCbTimer{}
+	ctrl := recorderControllerFactory(t, timer, recorder)
+
+	label := pomoController.SessionLabel{Task: "report"}
+	if err := ctrl.Label(start, label); err != nil {
+		t.Fatal(err)
+	}
+
+	// WORK WITH 3 MINUTES PAUSE. ENDS BY TIMER.
+	if err := ctrl.Play(start); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.Pause(start.Add(10 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.Play(start.Add(13 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	// SHORT BREAK SKIPPED AFTER 1 MINUTE
+	if err := ctrl.Skip(start.Add(29 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	// WORK STOPPED AFTER 5 MINUTES
+	if err := ctrl.Stop(start.Add(34 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	intervals := journal.intervals
+	if len(intervals) != 3 {
+		t.Fatalf("Expected 3 intervals, got %d", len(intervals))
+	}
+
+	work := intervals[0]
+	if work.State != pomoController.PomoControllerWork {
+		t.Fatalf("First interval state is %s", work.State)
+	}
+	if work.PausedTime != 3*time.Minute {
+		t.Fatalf("Paused time is %s, expected 3m", work.PausedTime)
+	}
+	if work.TimeSpent != 25*time.Minute {
+		t.Fatalf("Time spent is %s, expected 25m", work.TimeSpent)
+	}
+	if work.Skipped || work.Stopped {
+		t.Fatalf("Interval ended by timer marked as skipped or stopped")
+	}
+	if work.Label.Task != label.Task {
+		t.Fatalf("Interval label is %v, expected %v", work.Label, label)
+	}
+
+	brk := intervals[1]
+	if brk.State != pomoController.PomoControllerShortBreak || !brk.Skipped {
+		t.Fatalf("Second interval is %+v, expected skipped short break", brk)
+	}
+	if brk.TimeSpent != time.Minute {
+		t.Fatalf("Time spent is %s, expected 1m", brk.TimeSpent)
+	}
+
+	last := intervals[2]
+	if last.State != pomoController.PomoControllerWork || !last.Stopped {
+		t.Fatalf("Last interval is %+v, expected stopped work", last)
+	}
+	if last.TimeSpent != 5*time.Minute {
+		t.Fatalf("Time spent is %s, expected 5m", last.TimeSpent)
+	}
+}
+
+func TestRecorderCaughtUp(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	journal := &memoryJournal{}
+	recorder := &Recorder{Journal: journal}
+
+	recorder.OnPlay(pomoController.PomoControllerEventArgsPlay{
+		At:           start,
+		CurrentState: pomoController.PomoControllerWork,
+	})
+
+	// SERVER DOWN FROM 9:10. WORK AND BREAK CAUGHT UP ON RESTORE AT 9:40.
+	recorder.OnNextState(pomoController.PomoControllerEventArgsNextState{
+		At:           start.Add(25 * time.Minute),
+		CurrentState: pomoController.PomoControllerWork,
+		NextState:    pomoController.PomoControllerShortBreak,
+		CaughtUp:     true,
+	})
+	recorder.OnNextState(pomoController.PomoControllerEventArgsNextState{
+		At:           start.Add(30 * time.Minute),
+		CurrentState: pomoController.PomoControllerShortBreak,
+		NextState:    pomoController.PomoControllerWork,
+		CaughtUp:     true,
+	})
+	recorder.OnPlay(pomoController.PomoControllerEventArgsPlay{
+		At:           start.Add(40 * time.Minute),
+		CurrentState: pomoController.PomoControllerWork,
+	})
+	recorder.OnStop(pomoController.PomoControllerEventArgsStop{
+		At:           start.Add(50 * time.Minute),
+		CurrentState: pomoController.PomoControllerWork,
+	})
+
+	intervals := journal.intervals
+	if len(intervals) != 1 {
+		t.Fatalf("Expected only the observed interval, got %+v", intervals)
+	}
+	if got := intervals[0]; !got.Start.Equal(start.Add(40*time.Minute)) || got.TimeSpent != 10*time.Minute {
+		t.Fatalf("Interval is %+v, expected 10m of work from 9:40", got)
+	}
+}
+
+// WORK STOPPED IN OVERTIME WAS COMPLETE.
+func TestRecorderStopOvertime(t *testing.T) {
+	start := time.Date(2024, 12, 06, 9, 0, 0, 0, time.UTC)
+	journal := &memoryJournal{}
+	recorder := &Recorder{Journal: journal}
+	timer := &pomoTimer.MockCbTimer{}
+	ctrl := recorderControllerFactory(
+		t,
+		timer,
+		recorder,
+		pomoController.PomoControllerOptionAdvancePolicy(
+			pomoController.PomoControllerAdvancePolicy{ManualAfterWork: true},
+		),
+	)
+
+	if err := ctrl.Play(start); err != nil {
+		t.Fatal(err)
+	}
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.Stop(start.Add(30 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(journal.intervals) != 1 {
+		t.Fatalf("Expected 1 interval, got %d", len(journal.intervals))
+	}
+	work := journal.intervals[0]
+	if work.Stopped || work.Skipped {
+		t.Fatalf("Interval is %+v, expected complete work", work)
+	}
+	if work.Overtime != 5*time.Minute {
+		t.Fatalf("Overtime is %s, expected 5m", work.Overtime)
+	}
+}
@@ -0,0 +1,174 @@
+// Aggregated statistics over the interval history.
+
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+)
+
+// Statistics of the work intervals of a group. Minutes are used instead of
+// durations so json output is easy to consume. Focused minutes are those of
+// completed work up to its end of state. Time past it is overtime.
+type ReportRow struct {
+	Key                 string
+	Completed           int
+	FocusedMinutes      float64
+	OvertimeMinutes     float64
+	Skipped             int
+	AveragePauseMinutes float64
+	LongestStreak       int
+}
+
+// Function that names the group an interval belongs to.
+type ReportKeyF func(interval Interval) string
+
+// Days start at rolloverHour on loc, same as the daily goal. Intervals belong
+// to the day they were done on, which is when the goal counts them.
+func reportDay(interval Interval, loc *time.Location, rolloverHour int) time.Time {
+	done := interval.End.Add(-interval.Overtime)
+	return done.In(loc).Add(-time.Duration(rolloverHour) * time.Hour)
+}
+
+func ReportByDay(loc *time.Location, rolloverHour int) ReportKeyF {
+	return func(interval Interval) string {
+		return reportDay(interval, loc, rolloverHour).Format(time.DateOnly)
+	}
+}
+
+func ReportByWeek(loc *time.Location, rolloverHour int) ReportKeyF {
+	return func(interval Interval) string {
+		year, week := reportDay(interval, loc, rolloverHour).ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+}
+
+func ReportByLabel(interval Interval) string {
+	if interval.Label.Task == "" {
+		return "-"
+	}
+	return interval.Label.Task
+}
+
+func ReportByProject(interval Interval) string {
+	if interval.Label.Project == "" {
+		return "-"
+	}
+	return interval.Label.Project
+}
+
+// Return report key function given its name.
+func ReportKeyByName(name string, loc *time.Location, rolloverHour int) (ReportKeyF, error) {
+	switch name {
+	case "day":
+		return ReportByDay(loc, rolloverHour), nil
+	case "week":
+		return ReportByWeek(loc, rolloverHour), nil
+	case "label":
+		return ReportByLabel, nil
+	case "project":
+		return ReportByProject, nil
+	}
+	return nil, fmt.Errorf("%w: unknown report grouping %q", ErrInvalidReport, name)
+}
+
+// Aggregate work intervals. Completed are work intervals neither skipped,
+// stopped nor voided and a streak is a run of them without an unfinished one in between.
+// Breaks are left out. Rows are sorted by key.
+func BuildReport(intervals []Interval, key ReportKeyF) []ReportRow {
+	type acc struct {
+		row       ReportRow
+		nWork     int
+		pause     time.Duration
+		focused   time.Duration
+		overtime  time.Duration
+		curStreak int
+	}
+
+	sorted := make([]Interval, len(intervals))
+	copy(sorted, intervals)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	groups := map[string]*acc{}
+	for _, interval := range sorted {
+		if interval.State != pomoController.PomoControllerWork {
+			continue
+		}
+
+		k := key(interval)
+		a, ok := groups[k]
+		if !ok {
+			a = &acc{row: ReportRow{Key: k}}
+			groups[k] = a
+		}
+
+		a.nWork++
+		a.pause += interval.PausedTime
+		if interval.Skipped {
+			a.row.Skipped++
+		}
+
+		if interval.Skipped || interval.Stopped || interval.Voided {
+			a.curStreak = 0
+			continue
+		}
+
+		a.focused += interval.TimeSpent - interval.Overtime
+		a.overtime += interval.Overtime
+		a.row.Completed++
+		a.curStreak++
+		if a.curStreak > a.row.LongestStreak {
+			a.row.LongestStreak = a.curStreak
+		}
+	}
+
+	rows := make([]ReportRow, 0, len(groups))
+	for _, a := range groups {
+		a.row.FocusedMinutes = a.focused.Minutes()
+		a.row.OvertimeMinutes = a.overtime.Minutes()
+		if a.nWork > 0 {
+			a.row.AveragePauseMinutes = (a.pause / time.Duration(a.nWork)).Minutes()
+		}
+		rows = append(rows, a.row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Key < rows[j].Key
+	})
+	return rows
+}
+
+// Print rows as an aligned text table.
+func WriteReportText(w io.Writer, rows []ReportRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "KEY\tCOMPLETED\tFOCUSED (m)\tOVERTIME (m)\tSKIPPED\tAVG PAUSE (m)\tLONGEST STREAK\t")
+	for _, r := range rows {
+		fmt.Fprintf(
+			tw,
+			"%s\t%d\t%.1f\t%.1f\t%d\t%.1f\t%d\t\n",
+			r.Key,
+			r.Completed,
+			r.FocusedMinutes,
+			r.OvertimeMinutes,
+			r.Skipped,
+			r.AveragePauseMinutes,
+			r.LongestStreak,
+		)
+	}
+	return tw.Flush()
+}
+
+// Print rows as an indented json array.
+func WriteReportJson(w io.Writer, rows []ReportRow) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(rows)
+}
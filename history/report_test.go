@@ -0,0 +1,143 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+)
+
+func reportWork(start time.Time, spent, paused time.Duration, skipped bool, task string) Interval {
+	return Interval{
+		State:      pomoController.PomoControllerWork,
+		Start:      start,
+		End:        start.Add(spent + paused),
+		TimeSpent:  spent,
+		PausedTime: paused,
+		Skipped:    skipped,
+		Label:      pomoController.SessionLabel{Task: task},
+	}
+}
+
+func reportIntervals() []Interval {
+	day1 := time.Date(2024, 12, 2, 9, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, 12, 3, 9, 0, 0, 0, time.UTC)
+	return []Interval{
+		reportWork(day1, 25*time.Minute, 2*time.Minute, false, "report"),
+		{
+			State:     pomoController.PomoControllerShortBreak,
+			Start:     day1.Add(27 * time.Minute),
+			TimeSpent: time.Minute,
+			Skipped:   true,
+		},
+		reportWork(day1.Add(30*time.Minute), 25*time.Minute, 0, false, "report"),
+		reportWork(day1.Add(60*time.Minute), 10*time.Minute, 4*time.Minute, true, "review"),
+		{
+			State:     pomoController.PomoControllerWork,
+			Start:     day1.Add(80 * time.Minute),
+			End:       day1.Add(110 * time.Minute),
+			TimeSpent: 30 * time.Minute,
+			Overtime:  5 * time.Minute,
+			Label:     pomoController.SessionLabel{Task: "review"},
+		},
+		{
+			State:     pomoController.PomoControllerWork,
+			Start:     day1.Add(110 * time.Minute),
+			End:       day1.Add(120 * time.Minute),
+			TimeSpent: 10 * time.Minute,
+			Stopped:   true,
+			Label:     pomoController.SessionLabel{Task: "review"},
+		},
+		reportWork(day2, 25*time.Minute, 0, false, ""),
+	}
+}
+
+func TestReportByDay(t *testing.T) {
+	rows := BuildReport(reportIntervals(), ReportByDay(time.UTC, 0))
+
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+
+	// FOCUSED TIME IS THAT OF COMPLETED WORK WITHOUT OVERTIME. SKIPPED BREAKS
+	// AND STOPPED WORK DON'T ADD UP.
+	day1 := rows[0]
+	expected := ReportRow{
+		Key:                 "2024-12-02",
+		Completed:           3,
+		FocusedMinutes:      75,
+		OvertimeMinutes:     5,
+		Skipped:             1,
+		AveragePauseMinutes: 1.2,
+		LongestStreak:       2,
+	}
+	if day1 != expected {
+		t.Fatalf("Day row is %+v, expected %+v", day1, expected)
+	}
+
+	if rows[1].Key != "2024-12-03" || rows[1].Completed != 1 {
+		t.Fatalf("Second day row is %+v", rows[1])
+	}
+}
+
+func TestReportByWeekAndLabel(t *testing.T) {
+	weekRows := BuildReport(reportIntervals(), ReportByWeek(time.UTC, 0))
+	if len(weekRows) != 1 || weekRows[0].Key != "2024-W49" || weekRows[0].Completed != 4 {
+		t.Fatalf("Unexpected week rows %+v", weekRows)
+	}
+
+	labelRows := BuildReport(reportIntervals(), ReportByLabel)
+	keys := []string{}
+	for _, r := range labelRows {
+		keys = append(keys, r.Key)
+	}
+	if strings.Join(keys, ",") != "-,report,review" {
+		t.Fatalf("Unexpected label keys %v", keys)
+	}
+}
+
+func TestReportOutput(t *testing.T) {
+	rows := BuildReport(reportIntervals(), ReportByDay(time.UTC, 0))
+
+	var text bytes.Buffer
+	if err := WriteReportText(&text, rows); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(text.String()), "\n"); len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got:\n%s", text.String())
+	}
+
+	var js bytes.Buffer
+	if err := WriteReportJson(&js, rows); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []ReportRow
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(rows) || decoded[0] != rows[0] {
+		t.Fatalf("Json round trip mismatch %+v", decoded)
+	}
+}
+
+// DAYS ARE THOSE OF THE DAILY GOAL.
+func TestReportByDayRollover(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	key := ReportByDay(loc, 4)
+
+	// DONE AT 01:30 LOCAL TIME COUNTS FOR THE DAY BEFORE.
+	lateNight := reportWork(time.Date(2024, 12, 6, 23, 5, 0, 0, time.UTC), 25*time.Minute, 0, false, "")
+	if day := key(lateNight); day != "2024-12-06" {
+		t.Fatalf("Day is %s, expected 2024-12-06", day)
+	}
+
+	// DONE AT 03:55 LOCAL TIME. OVERTIME PAST THE ROLLOVER DOESN'T MOVE IT.
+	overtime := reportWork(time.Date(2024, 12, 7, 1, 30, 0, 0, time.UTC), 35*time.Minute, 0, false, "")
+	overtime.Overtime = 10 * time.Minute
+	if day := key(overtime); day != "2024-12-06" {
+		t.Fatalf("Day is %s, expected 2024-12-06", day)
+	}
+}
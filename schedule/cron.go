@@ -0,0 +1,150 @@
+// Cron fields: minute, hour, day of month, month and day of week. Each takes
+// *, a value, a range a-b, a step */n or a-b/n, or a comma list of those.
+// Day of week goes from 0 (Sunday) to 6, 7 is Sunday too.
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Allowed values of a field as a bit set.
+type cronField struct {
+	bits uint64
+	// Written as *. Day of month and day of week match if either does when
+	// both are restricted, as in cron.
+	any bool
+}
+
+func (f cronField) has(v int) bool {
+	return f.bits&(1<<uint(v)) != 0
+}
+
+// Parse field allowing values from lo to hi.
+func parseCronField(text string, lo, hi int) (cronField, error) {
+	field := cronField{any: text == "*"}
+
+	for _, part := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepText)
+			if err != nil || s <= 0 {
+				return cronField{}, fmt.Errorf("%w: %s", ErrInvalidField, text)
+			}
+			step = s
+		}
+
+		from, to := lo, hi
+		if rangeText != "*" {
+			fromText, toText, isRange := strings.Cut(rangeText, "-")
+			f, err := strconv.Atoi(fromText)
+			if err != nil {
+				return cronField{}, fmt.Errorf("%w: %s", ErrInvalidField, text)
+			}
+			from, to = f, f
+			if isRange {
+				if to, err = strconv.Atoi(toText); err != nil {
+					return cronField{}, fmt.Errorf("%w: %s", ErrInvalidField, text)
+				}
+			} else if hasStep {
+				// a/n MEANS FROM a TO THE END.
+				to = hi
+			}
+		}
+
+		if from < lo || to > hi || from > to {
+			return cronField{}, fmt.Errorf("%w: %s", ErrInvalidField, text)
+		}
+		for v := from; v <= to; v += step {
+			field.bits |= 1 << uint(v)
+		}
+	}
+	return field, nil
+}
+
+// When a rule is due.
+type cronSpec struct {
+	minute cronField
+	hour   cronField
+	dom    cronField
+	month  cronField
+	dow    cronField
+}
+
+func parseCronSpec(fields []string) (cronSpec, error) {
+	var spec cronSpec
+	var err error
+
+	if spec.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return spec, err
+	}
+	if spec.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return spec, err
+	}
+	if spec.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return spec, err
+	}
+	if spec.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return spec, err
+	}
+	if spec.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return spec, err
+	}
+	// SUNDAY IS BOTH 0 AND 7.
+	if spec.dow.has(7) {
+		spec.dow.bits |= 1
+	}
+	return spec, nil
+}
+
+func (s cronSpec) matchDay(t time.Time) bool {
+	if !s.month.has(int(t.Month())) {
+		return false
+	}
+	dom := s.dom.has(t.Day())
+	dow := s.dow.has(int(t.Weekday()))
+	if !s.dom.any && !s.dow.any {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Longest wait for a match, enough for February 29.
+const cronSearchDays = 4*366 + 1
+
+// First time after t the spec is due, on t location. False if it never is.
+func (s cronSpec) next(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	y, m, d := t.Date()
+
+	for day := 0; day < cronSearchDays; day++ {
+		date := time.Date(y, m, d+day, 0, 0, 0, 0, loc)
+		if !s.matchDay(date) {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			if !s.hour.has(h) {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !s.minute.has(minute) {
+					continue
+				}
+				candidate := time.Date(date.Year(), date.Month(), date.Day(), h, minute, 0, 0, loc)
+				// SKIPPED BY A DAYLIGHT SAVING CHANGE.
+				if candidate.Hour() != h {
+					continue
+				}
+				if candidate.After(t) {
+					return candidate, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
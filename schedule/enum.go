@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ======
+// Action
+// ======
+
+// What a rule does to the controller when due.
+type Action int
+
+const (
+	// Start or resume. Nothing if already running.
+	ActionPlay Action = iota
+	// Stop. Nothing if already stopped.
+	ActionStop
+	// Pause. Nothing if not running.
+	ActionPause
+	// Start a long break right away, whatever the current state.
+	ActionLongBreak
+)
+
+func (a Action) String() string {
+
+	switch a {
+	case ActionPlay:
+		return "play"
+	case ActionStop:
+		return "stop"
+	case ActionPause:
+		return "pause"
+	case ActionLongBreak:
+		return "long"
+	}
+
+	panic("Impossible Action value")
+}
+
+func ParseAction(s string) (Action, error) {
+	switch strings.ToLower(s) {
+	case "play":
+		return ActionPlay, nil
+	case "stop":
+		return ActionStop, nil
+	case "pause":
+		return ActionPause, nil
+	case "long":
+		return ActionLongBreak, nil
+	}
+	return 0, fmt.Errorf("invalid schedule action: %s", s)
+}
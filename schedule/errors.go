@@ -0,0 +1,6 @@
+package schedule
+
+import "errors"
+
+var ErrInvalidRule = errors.New("invalid schedule rule")
+var ErrInvalidField = errors.New("invalid schedule field")
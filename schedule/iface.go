@@ -0,0 +1,41 @@
+// Schedule of controller actions at given times of the week, like play at 9
+// and stop at 18 on weekdays.
+
+package schedule
+
+import (
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"sync"
+	"time"
+)
+
+type pomoCtrl = pomoController.PomoControllerIface
+
+// Cron-like rule, e.g. `0 9 * * 1-5 play` or `0 13 * * 1-5 long 1h`.
+type Rule struct {
+	Text   string
+	Action Action
+	// Length of a forced long break. Configured duration if zero.
+	Duration time.Duration
+
+	spec cronSpec
+}
+
+// Runs the actions of its rules on the controller when due. Actions missed
+// while it was not running are not run afterwards.
+type Schedule struct {
+	Rules []Rule
+	// Rules are read on this location. Local if nil.
+	Location *time.Location
+	// Real clock if nil.
+	Clock pomoTimer.Clock
+	// Waits for the next action. Wall clock timer if nil.
+	Timer pomoTimer.PomoCtxTimerIface
+	// Controller to act on. Called on every action so it may create it.
+	Controller func() pomoCtrl
+	// Action errors other than being in the state asked for already.
+	OnError func(err error)
+
+	locker sync.Mutex
+}
@@ -0,0 +1,197 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"strings"
+	"time"
+)
+
+// =======
+// PARSING
+// =======
+
+// Parse rule made of five cron fields, the action and an optional duration
+// for long breaks.
+func ParseRule(text string) (Rule, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 6 && len(fields) != 7 {
+		return Rule{}, fmt.Errorf("%w: %s", ErrInvalidRule, text)
+	}
+
+	spec, err := parseCronSpec(fields[:5])
+	if err != nil {
+		return Rule{}, err
+	}
+
+	action, err := ParseAction(fields[5])
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rule := Rule{
+		Text:   strings.Join(fields, " "),
+		Action: action,
+		spec:   spec,
+	}
+
+	if len(fields) == 7 {
+		if action != ActionLongBreak {
+			return Rule{}, fmt.Errorf("%w: only long breaks take a duration: %s", ErrInvalidRule, text)
+		}
+		if rule.Duration, err = time.ParseDuration(fields[6]); err != nil || rule.Duration <= 0 {
+			return Rule{}, fmt.Errorf("%w: %s", ErrInvalidRule, text)
+		}
+	}
+	return rule, nil
+}
+
+// Parse rules separated by semicolons. Empty text is no rules.
+func ParseRules(text string) ([]Rule, error) {
+	rules := []Rule{}
+	for _, ruleText := range strings.Split(text, ";") {
+		if strings.TrimSpace(ruleText) == "" {
+			continue
+		}
+		rule, err := ParseRule(ruleText)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ========
+// SCHEDULE
+// ========
+
+func (s *Schedule) location() *time.Location {
+	if s.Location == nil {
+		return time.Local
+	}
+	return s.Location
+}
+
+// Created on first use.
+func (s *Schedule) timer() pomoTimer.PomoCtxTimerIface {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.Timer == nil {
+		s.Timer = &pomoTimer.WallTimer{Clock: s.Clock}
+	}
+	return s.Timer
+}
+
+func (s *Schedule) onError(err error) {
+	if s.OnError == nil {
+		return
+	}
+	s.OnError(err)
+}
+
+// Earliest time after now some rule is due and the rules due then, in order.
+func (s *Schedule) due(now time.Time) (time.Time, []Rule, bool) {
+	local := now.In(s.location())
+
+	var at time.Time
+	rules := []Rule{}
+	for _, rule := range s.Rules {
+		next, ok := rule.spec.next(local)
+		switch {
+		case !ok:
+		case len(rules) == 0 || next.Before(at):
+			at = next
+			rules = []Rule{rule}
+		case next.Equal(at):
+			rules = append(rules, rule)
+		}
+	}
+	return at, rules, len(rules) > 0
+}
+
+// Next action after now. Several actions at the same time are joined with
+// commas.
+func (s *Schedule) Next(now time.Time) (pomoController.PomoControllerScheduled, bool) {
+	at, rules, ok := s.due(now)
+	if !ok {
+		return pomoController.PomoControllerScheduled{}, false
+	}
+
+	actions := make([]string, len(rules))
+	for i, rule := range rules {
+		actions[i] = rule.Action.String()
+	}
+	return pomoController.PomoControllerScheduled{
+		At:     at,
+		Action: strings.Join(actions, ","),
+	}, true
+}
+
+// Run every action when due until ctx is done. Returns right away.
+func (s *Schedule) Start(ctx context.Context) error {
+	return s.wait(ctx, pomoTimer.ClockOrReal(s.Clock).Now())
+}
+
+// Wait for the next rules after now.
+func (s *Schedule) wait(ctx context.Context, now time.Time) error {
+	at, rules, ok := s.due(now)
+	if !ok {
+		return nil
+	}
+
+	return s.timer().WaitCtx(ctx, at.Sub(now), func() {
+		// LATE AFTER A SUSPEND. ACTIONS MISSED MEANWHILE ARE SKIPPED.
+		now := pomoTimer.ClockOrReal(s.Clock).Now()
+		for _, rule := range rules {
+			if err := s.apply(ctx, now, rule); err != nil {
+				s.onError(err)
+			}
+		}
+		if err := s.wait(ctx, now); err != nil {
+			s.onError(err)
+		}
+	})
+}
+
+// Run rule action. Being in the state asked for already is not an error.
+func (s *Schedule) apply(ctx context.Context, now time.Time, rule Rule) error {
+	ctrl := s.Controller()
+
+	switch rule.Action {
+	case ActionPlay:
+		return ignore(ctrl.PlayCtx(ctx, now), pomoController.ErrRunningTimer)
+	case ActionStop:
+		return ignore(ctrl.StopCtx(ctx, now), pomoController.ErrStoppedTimer)
+	case ActionPause:
+		return ignore(
+			ctrl.PauseCtx(ctx, now),
+			pomoController.ErrPausedTimer,
+			pomoController.ErrStoppedTimer,
+			pomoController.ErrOvertimeTimer,
+		)
+	case ActionLongBreak:
+		if err := ctrl.SkipToCtx(ctx, now, pomoSession.PomoSessionLongBreak); err != nil {
+			return err
+		}
+		if rule.Duration <= 0 {
+			return nil
+		}
+		timeLeft := time.Duration(ctrl.Tick(now).TimeLeft)
+		return ctrl.ExtendCtx(ctx, now, rule.Duration-timeLeft)
+	}
+	return nil
+}
+
+func ignore(err error, expected ...error) error {
+	for _, e := range expected {
+		if errors.Is(err, e) {
+			return nil
+		}
+	}
+	return err
+}
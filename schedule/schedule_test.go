@@ -0,0 +1,222 @@
+package schedule
+
+import (
+	"context"
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+// Monday.
+var scheduleRefNow = time.Date(2024, 12, 02, 8, 0, 0, 0, time.UTC)
+
+const workdayRules = "0 9 * * 1-5 play; 0 13 * * 1-5 long 1h; 0 18 * * 1-5 stop"
+
+func mustParseRules(t *testing.T, text string) []Rule {
+	rules, err := ParseRules(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+// Controller and schedule sharing a fake clock.
+func scheduledControllerFactory(
+	t *testing.T,
+	text string,
+) (*pomoController.PomoController, *Schedule, *pomoTimer.FakeClock) {
+	clock := pomoTimer.NewFakeClock(scheduleRefNow)
+	schedule := &Schedule{
+		Rules:    mustParseRules(t, text),
+		Location: time.UTC,
+		Clock:    clock,
+		Timer:    &pomoTimer.PomoTimer{Clock: clock},
+		OnError: func(err error) {
+			t.Errorf("Unexpected schedule error %v", err)
+		},
+	}
+
+	ctrl, err := pomoController.FakeClockControllerFactory(
+		clock,
+		pomoController.PomoControllerOptionSchedule(schedule),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule.Controller = func() pomoCtrl { return ctrl }
+	return ctrl, schedule, clock
+}
+
+// =====
+// TESTS
+// =====
+
+func TestParseRule(t *testing.T) {
+	cases := map[string]Action{
+		"0 9 * * 1-5 play":       ActionPlay,
+		"*/15 * * * * pause":     ActionPause,
+		"30 17 1,15 * 0,6 stop":  ActionStop,
+		"0 13 * * MON long":      -1,
+		"0 13 * * 1-5 long 45m":  ActionLongBreak,
+		"0 9 * * 1-5 play 1h":    -1,
+		"60 9 * * * play":        -1,
+		"0 9 * * * jump":         -1,
+		"0 9 * *":                -1,
+		"0 9-8 * * * play":       -1,
+		"0 9 * * 1-5/2 stop":     ActionStop,
+		"0 13 * * 1-5 long -10m": -1,
+	}
+
+	for text, expected := range cases {
+		rule, err := ParseRule(text)
+		if expected < 0 {
+			if err == nil {
+				t.Fatalf("Expected error parsing %q", text)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", text, err)
+		}
+		if rule.Action != expected {
+			t.Fatalf("Expected %s on %q, got %s", expected, text, rule.Action)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	schedule := &Schedule{
+		Rules:    mustParseRules(t, workdayRules+"; 0 18 * * 5 pause"),
+		Location: time.UTC,
+	}
+
+	cases := []struct {
+		now    time.Time
+		at     time.Time
+		action string
+	}{
+		{scheduleRefNow, scheduleRefNow.Add(time.Hour), "play"},
+		// DUE NOW IS ALREADY PAST.
+		{scheduleRefNow.Add(time.Hour), scheduleRefNow.Add(5 * time.Hour), "long"},
+		// FRIDAY EVENING THEN MONDAY.
+		{scheduleRefNow.AddDate(0, 0, 4).Add(9 * time.Hour), scheduleRefNow.AddDate(0, 0, 4).Add(10 * time.Hour), "stop,pause"},
+		{scheduleRefNow.AddDate(0, 0, 4).Add(11 * time.Hour), scheduleRefNow.AddDate(0, 0, 7).Add(time.Hour), "play"},
+	}
+
+	for _, c := range cases {
+		next, ok := schedule.Next(c.now)
+		if !ok {
+			t.Fatalf("Expected next action after %s", c.now)
+		}
+		if !next.At.Equal(c.at) || next.Action != c.action {
+			t.Fatalf("Expected %s at %s after %s, got %s at %s", c.action, c.at, c.now, next.Action, next.At)
+		}
+	}
+
+	if _, ok := (&Schedule{}).Next(scheduleRefNow); ok {
+		t.Fatalf("Expected no next action without rules")
+	}
+}
+
+// RESTRICTED DAY OF MONTH AND DAY OF WEEK MATCH IF EITHER DOES.
+func TestScheduleDayOfMonthOrWeek(t *testing.T) {
+	schedule := &Schedule{
+		Rules:    mustParseRules(t, "0 9 15 * 0 play"),
+		Location: time.UTC,
+	}
+
+	// SUNDAY 8TH BEFORE THE 15TH.
+	next, _ := schedule.Next(scheduleRefNow)
+	if expected := time.Date(2024, 12, 8, 9, 0, 0, 0, time.UTC); !next.At.Equal(expected) {
+		t.Fatalf("Expected %s, got %s", expected, next.At)
+	}
+	next, _ = schedule.Next(next.At)
+	if expected := time.Date(2024, 12, 15, 9, 0, 0, 0, time.UTC); !next.At.Equal(expected) {
+		t.Fatalf("Expected %s, got %s", expected, next.At)
+	}
+}
+
+func TestScheduleWorkday(t *testing.T) {
+	ctrl, schedule, clock := scheduledControllerFactory(t, workdayRules)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := schedule.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	status := ctrl.Status()
+	if status.State != pomoController.PomoControllerStopped {
+		t.Fatalf("Expected stopped before 9, got %s", status.State)
+	}
+	if status.Scheduled == nil || status.Scheduled.Action != "play" {
+		t.Fatalf("Expected play scheduled, got %+v", status.Scheduled)
+	}
+
+	clock.AdvanceTo(scheduleRefNow.Add(time.Hour))
+	if st := ctrl.Status().State; st != pomoController.PomoControllerWork {
+		t.Fatalf("Expected work at 9, got %s", st)
+	}
+
+	// LUNCH.
+	clock.AdvanceTo(scheduleRefNow.Add(5 * time.Hour))
+	status = ctrl.Status()
+	if status.State != pomoController.PomoControllerLongBreak {
+		t.Fatalf("Expected long break at 13, got %s", status.State)
+	}
+	if time.Duration(*status.TimeLeft) != time.Hour {
+		t.Fatalf("Expected 1h lunch, got %s", time.Duration(*status.TimeLeft))
+	}
+	if status.Scheduled == nil || status.Scheduled.Action != "stop" {
+		t.Fatalf("Expected stop scheduled, got %+v", status.Scheduled)
+	}
+
+	clock.AdvanceTo(scheduleRefNow.Add(6 * time.Hour))
+	if st := ctrl.Status().State; st != pomoController.PomoControllerWork {
+		t.Fatalf("Expected work after lunch, got %s", st)
+	}
+
+	clock.AdvanceTo(scheduleRefNow.Add(10 * time.Hour))
+	status = ctrl.Status()
+	if status.State != pomoController.PomoControllerStopped {
+		t.Fatalf("Expected stopped at 18, got %s", status.State)
+	}
+	tomorrow := scheduleRefNow.AddDate(0, 0, 1).Add(time.Hour)
+	if status.Scheduled == nil || !status.Scheduled.At.Equal(tomorrow) {
+		t.Fatalf("Expected play tomorrow at 9, got %+v", status.Scheduled)
+	}
+
+	// NOTHING RUNS ONCE CANCELLED.
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	clock.AdvanceTo(tomorrow)
+	if st := ctrl.Status().State; st != pomoController.PomoControllerStopped {
+		t.Fatalf("Expected schedule to end with its context, got %s", st)
+	}
+}
+
+// MANUAL ACTIONS IN BETWEEN ARE NOT ERRORS.
+func TestScheduleAlreadyInState(t *testing.T) {
+	ctrl, schedule, clock := scheduledControllerFactory(t, "0 9 * * * play; 0 10 * * * pause; 0 11 * * * stop")
+
+	if err := schedule.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.Play(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.AdvanceTo(scheduleRefNow.Add(time.Hour))
+	if err := ctrl.Stop(clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clock.AdvanceTo(scheduleRefNow.Add(3 * time.Hour))
+	if st := ctrl.Status().State; st != pomoController.PomoControllerStopped {
+		t.Fatalf("Expected stopped, got %s", st)
+	}
+}
@@ -1,34 +1,56 @@
 #!/bin/sh
 
 # Script to be ran by pomogo on every event.
-# The following environment variables are included:
-# 
-# POMO_EVENT: Reason why the hook was called:
-#   - Error: on error.
+# The full list of environment variables is in the README. The most useful:
+#
+# POMOGO_EVENT: Reason why the hook was called:
+#   - Error: on error. See POMOGO_ERROR
 #   - EndOfState: When a state time ends.
 #   - Play: On successful start or resume event.
-#   - Pause: On successful pause request.
+#   - Pause: On successful pause request. See POMOGO_PAUSE_REASON
 #   - Stop: On successful stop.
+#   - Label: On session label change.
+#   - Extend: When the current state is extended or shortened.
+#   - Overtime: When a state is over but the next one waits for play.
+#   - Interrupt: When the work interval is interrupted. See POMOGO_INTERRUPTION_*
+#   - GoalReached: When the daily goal is met. See POMOGO_GOAL_*
+#   - Warning: Some time before the end of a state.
 #
-# POMO_STATUS: When in error is the error message. The current status otherwise. It may be:
+# POMOGO_STATE, POMOGO_NEXT_STATE: Current and next state. It may be:
 #   - Work
 #   - ShortBreak
 #   - LongBreak
 #
-# POMO_AT: Iso Date of the moment the event was triggered.
+# POMOGO_TIMESTAMP: RFC 3339 time of the event.
+#
+# POMOGO_TIME_SPENT_SECONDS, POMOGO_TIME_LEFT_SECONDS: Of the current state.
+#
+# POMOGO_WORKED_SESSIONS: Work sessions completed so far.
+#
+# POMOGO_TASK, POMOGO_PROJECT, POMOGO_TAGS: Session label. Tags are comma
+# separated.
+#
+# The event is also written to stdin as json, e.g. `jq -r .Data.Label.Task`.
 
-case $POMO_EVENT in
-    "Pause") 
-        notify-send "⏸ Pause" "Paused $POMOGO_STATUS"
+case $POMOGO_EVENT in
+    "Pause")
+        notify-send "⏸ Pause" "Paused $POMOGO_STATE $POMOGO_PAUSE_REASON"
         ;;
-    "Stop") 
-        notify-send "⏹ Pause" "Paused $POMOGO_STATUS"
+    "Stop")
+        notify-send "⏹ Stop" "Stopped $POMOGO_STATE"
         ;;
-    "Error") 
-        notify-send "⚠ Error" "$POMOGO_STATUS"
+    "Warning")
+        notify-send "🔔 $(((${POMOGO_TIME_LEFT_SECONDS%.*} + 30) / 60))m left" "$POMOGO_NEXT_STATE next"
         ;;
-    *) # Play, EndOfState
-        notify-send "$POMOGO_STATUS"
+    "Error")
+        notify-send "⚠ Error" "$POMOGO_ERROR"
+        ;;
+    "EndOfState")
+        notify-send "$POMOGO_NEXT_STATE" "$POMOGO_WORKED_SESSIONS work sessions done"
+        ;;
+    "Play")
+        notify-send "$POMOGO_STATE"
+        ;;
+    *) # Nothing to show for other events.
         ;;
 esac
-
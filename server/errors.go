@@ -0,0 +1,5 @@
+package server
+
+import "errors"
+
+var ErrTickStreamNotFound = errors.New("tick stream not found or closed")
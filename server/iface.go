@@ -2,10 +2,18 @@ package server
 
 import (
 	pomoController "github.com/FernandoAFS/pomogo/controller"
+	"time"
 )
 
 type pomoStatus = pomoController.PomoControllerStatus
 type pomoCtrl = pomoController.PomoControllerIface
+type pomoLabel = pomoController.SessionLabel
+
+// Interrupt action arguments.
+type InterruptRequest struct {
+	Kind pomoController.PomoInterruptionKind
+	Note string
+}
 
 type PomogoSessionServer interface {
 	Status(
@@ -17,7 +25,7 @@ type PomogoSessionServer interface {
 		reply *pomoController.PomoControllerStatus,
 	) error
 	Play(
-		request struct{},
+		request pomoController.SessionLabel,
 		reply *pomoController.PomoControllerStatus,
 	) error
 	Skip(
@@ -28,12 +36,40 @@ type PomogoSessionServer interface {
 		request struct{},
 		reply *pomoController.PomoControllerStatus,
 	) error
+	Label(
+		request pomoController.SessionLabel,
+		reply *pomoController.PomoControllerStatus,
+	) error
+	Extend(
+		request time.Duration,
+		reply *pomoController.PomoControllerStatus,
+	) error
+	Interrupt(
+		request InterruptRequest,
+		reply *pomoController.PomoControllerStatus,
+	) error
+	OpenTicks(
+		request time.Duration,
+		reply *uint64,
+	) error
+	NextTick(
+		request uint64,
+		reply *pomoController.PomoControllerTick,
+	) error
+	CloseTicks(
+		request uint64,
+		reply *struct{},
+	) error
 }
 
 type PomogoClient interface {
 	Status() (*pomoStatus, error)
 	Pause() (*pomoStatus, error)
-	Play() (*pomoStatus, error)
+	Play(label pomoLabel) (*pomoStatus, error)
 	Skip() (*pomoStatus, error)
 	Stop() (*pomoStatus, error)
+	Label(label pomoLabel) (*pomoStatus, error)
+	Extend(delta time.Duration) (*pomoStatus, error)
+	Interrupt(request InterruptRequest) (*pomoStatus, error)
+	Watch(every time.Duration, cb func(tick pomoController.PomoControllerTick) error) error
 }
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	pomoController "github.com/FernandoAFS/pomogo/controller"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
 	"log/slog"
 	"net"
 	"net/http"
@@ -18,6 +20,22 @@ import (
 // directly from the server object.
 type SingleSessionServer struct {
 	container *pomoController.SingleControllerContainer
+	// Time source for actions. Real clock if nil.
+	clock pomoTimer.Clock
+	ticks tickStreams
+	// Root context of every action. Never done if nil.
+	ctx context.Context
+}
+
+func (c *SingleSessionServer) now() time.Time {
+	return pomoTimer.ClockOrReal(c.clock).Now()
+}
+
+func (c *SingleSessionServer) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
 }
 
 // 100% private dry method
@@ -52,10 +70,10 @@ func (c *SingleSessionServer) Pause(
 	request struct{},
 	reply *pomoController.PomoControllerStatus,
 ) error {
-	now := time.Now()
+	now := c.now()
 	return c.doNowCb(
 		func(ctrl pomoCtrl) error {
-			if err := ctrl.Pause(now); err != nil {
+			if err := ctrl.PauseCtx(c.context(), now); err != nil {
 				return err
 			}
 			*reply = ctrl.Status()
@@ -64,13 +82,14 @@ func (c *SingleSessionServer) Pause(
 }
 
 // CREATE NEW INSTANCE AND START CONTROLLER COUNTING.
+// A non empty label is attached to the interval played so the play event
+// carries it.
 func (c *SingleSessionServer) Play(
-	request struct{},
+	request pomoController.SessionLabel,
 	reply *pomoController.PomoControllerStatus,
 ) error {
 	ctrl := c.container.CreateController()
-	now := time.Now()
-	if err := ctrl.Play(now); err != nil {
+	if err := ctrl.PlayLabelCtx(c.context(), c.now(), request); err != nil {
 		return err
 	}
 	*reply = ctrl.Status()
@@ -81,10 +100,10 @@ func (c *SingleSessionServer) Skip(
 	request struct{},
 	reply *pomoController.PomoControllerStatus,
 ) error {
-	now := time.Now()
+	now := c.now()
 	return c.doNowCb(
 		func(ctrl pomoCtrl) error {
-			if err := ctrl.Skip(now); err != nil {
+			if err := ctrl.SkipCtx(c.context(), now); err != nil {
 				return err
 			}
 			*reply = ctrl.Status()
@@ -96,10 +115,54 @@ func (c *SingleSessionServer) Stop(
 	request struct{},
 	reply *pomoController.PomoControllerStatus,
 ) error {
-	now := time.Now()
+	now := c.now()
+	return c.doNowCb(
+		func(ctrl pomoCtrl) error {
+			if err := ctrl.StopCtx(c.context(), now); err != nil {
+				return err
+			}
+			*reply = ctrl.Status()
+			return nil
+		})
+}
+
+// Label may be set before the first play so it creates the controller.
+func (c *SingleSessionServer) Label(
+	request pomoController.SessionLabel,
+	reply *pomoController.PomoControllerStatus,
+) error {
+	ctrl := c.container.CreateController()
+	now := c.now()
+	if err := ctrl.LabelCtx(c.context(), now, request); err != nil {
+		return err
+	}
+	*reply = ctrl.Status()
+	return nil
+}
+
+func (c *SingleSessionServer) Extend(
+	request time.Duration,
+	reply *pomoController.PomoControllerStatus,
+) error {
+	now := c.now()
 	return c.doNowCb(
 		func(ctrl pomoCtrl) error {
-			if err := ctrl.Stop(now); err != nil {
+			if err := ctrl.ExtendCtx(c.context(), now, request); err != nil {
+				return err
+			}
+			*reply = ctrl.Status()
+			return nil
+		})
+}
+
+func (c *SingleSessionServer) Interrupt(
+	request InterruptRequest,
+	reply *pomoController.PomoControllerStatus,
+) error {
+	now := c.now()
+	return c.doNowCb(
+		func(ctrl pomoCtrl) error {
+			if err := ctrl.InterruptCtx(c.context(), now, request.Kind, request.Note); err != nil {
 				return err
 			}
 			*reply = ctrl.Status()
@@ -135,12 +198,17 @@ type SingleSessionClient struct {
 // Simply call a method given the string name and return the response as a
 // pomodoro status
 func (c *SingleSessionClient) callMethod(method string) (*pomoStatus, error) {
+	return c.callMethodArgs(method, struct{}{})
+}
+
+// Same as callMethod with request arguments.
+func (c *SingleSessionClient) callMethodArgs(method string, args any) (*pomoStatus, error) {
 	var resp pomoStatus
 	callName := DefaultServerName + "." + method
 
-	slog.Debug("Making request", "method", callName, "response", resp)
+	slog.Debug("Making request", "method", callName, "args", args)
 
-	if err := c.client.Call(callName, struct{}{}, &resp); err != nil {
+	if err := c.client.Call(callName, args, &resp); err != nil {
 		return nil, err
 	}
 
@@ -157,8 +225,8 @@ func (c *SingleSessionClient) Pause() (*pomoStatus, error) {
 	return c.callMethod("Pause")
 }
 
-func (c *SingleSessionClient) Play() (*pomoStatus, error) {
-	return c.callMethod("Play")
+func (c *SingleSessionClient) Play(label pomoLabel) (*pomoStatus, error) {
+	return c.callMethodArgs("Play", label)
 }
 
 func (c *SingleSessionClient) Skip() (*pomoStatus, error) {
@@ -169,6 +237,41 @@ func (c *SingleSessionClient) Stop() (*pomoStatus, error) {
 	return c.callMethod("Stop")
 }
 
+func (c *SingleSessionClient) Label(label pomoLabel) (*pomoStatus, error) {
+	return c.callMethodArgs("Label", label)
+}
+
+func (c *SingleSessionClient) Extend(delta time.Duration) (*pomoStatus, error) {
+	return c.callMethodArgs("Extend", delta)
+}
+
+func (c *SingleSessionClient) Interrupt(request InterruptRequest) (*pomoStatus, error) {
+	return c.callMethodArgs("Interrupt", request)
+}
+
+// Call cb on every tick of a stream from the server until cb returns an
+// error. The stream is closed on the way out.
+func (c *SingleSessionClient) Watch(
+	every time.Duration,
+	cb func(tick pomoController.PomoControllerTick) error,
+) error {
+	var id uint64
+	if err := c.client.Call(DefaultServerName+".OpenTicks", every, &id); err != nil {
+		return err
+	}
+	defer c.client.Call(DefaultServerName+".CloseTicks", id, &struct{}{})
+
+	for {
+		var tick pomoController.PomoControllerTick
+		if err := c.client.Call(DefaultServerName+".NextTick", id, &tick); err != nil {
+			return err
+		}
+		if err := cb(tick); err != nil {
+			return err
+		}
+	}
+}
+
 // Initializes rpc client and returns wrapper
 func PomogoRpcClientFactory(protocol, address string) (*SingleSessionClient, error) {
 
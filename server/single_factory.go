@@ -1,7 +1,11 @@
 package server
 
 import (
+	"context"
+	"errors"
 	pomoController "github.com/FernandoAFS/pomogo/controller"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"io/fs"
 	"net"
 	"net/rpc"
 	"os"
@@ -60,6 +64,25 @@ func SingleServerContainerOpt(
 	}
 }
 
+// Set time source of the server actions.
+func SingleServerClockOpt(clock pomoTimer.Clock) SServerFuncOpt {
+	return func(ss *SingleSessionServer) (SServerFuncOpt, error) {
+		prev := ss.clock
+		ss.clock = clock
+		return SingleServerClockOpt(prev), nil
+	}
+}
+
+// Set root context of the server actions. Once done actions fail and pending
+// tick polls return.
+func SingleServerContextOpt(ctx context.Context) SServerFuncOpt {
+	return func(ss *SingleSessionServer) (SServerFuncOpt, error) {
+		prev := ss.ctx
+		ss.ctx = ctx
+		return SingleServerContextOpt(prev), nil
+	}
+}
+
 // Register the ssServer on an rpc server from server factory (can use
 // rpc.Server directly as factory)
 func SingleServerRpcRegisterOpt(
@@ -80,7 +103,8 @@ func SingleServerRpcRegisterOpt(
 			return nil, err
 		}
 		return func(ss *SingleSessionServer) (SServerFuncOpt, error) {
-			if err := l.Close(); err != nil {
+			// MAY BE CLOSED ALREADY ON SHUTDOWN.
+			if err := l.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 				return nil, err
 			}
 			return SingleServerRpcRegisterOpt(protocol, address, serverFactory, onListen), nil
@@ -103,7 +127,8 @@ func SingleServerRpcUnixRegOpt(
 		}
 
 		return func(ss *SingleSessionServer) (SServerFuncOpt, error) {
-			if err := os.Remove(address); err != nil {
+			// CLOSING THE LISTENER REMOVES THE SOCKET TOO.
+			if err := os.Remove(address); err != nil && !errors.Is(err, fs.ErrNotExist) {
 				return nil, err
 			}
 			return unreg(ss)
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"errors"
 	pomoController "github.com/FernandoAFS/pomogo/controller"
 	pomoSession "github.com/FernandoAFS/pomogo/session"
@@ -86,7 +87,8 @@ func TestSSStartstop(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		st, err := ssC.Play()
+		label := pomoController.SessionLabel{Task: "test", Tags: []string{"a"}}
+		st, err := ssC.Play(label)
 
 		if err != nil {
 			t.Fatal(err)
@@ -96,6 +98,10 @@ func TestSSStartstop(t *testing.T) {
 			t.Fatal("Expected non stopped status")
 		}
 
+		if st.Label == nil || st.Label.Task != label.Task {
+			t.Fatalf("Expected label %v and got %v", label, st.Label)
+		}
+
 		return nil
 	}
 
@@ -134,4 +140,188 @@ func TestSSStartstop(t *testing.T) {
 	}
 }
 
+// TICKS ARE KEPT UNTIL POLLED. SLOW CLIENTS ONLY GET THE LATEST.
+func TestSSTickStream(t *testing.T) {
+	clock := pomoTimer.NewFakeClock(time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC))
+	container := &pomoController.SingleControllerContainer{
+		ControllerFactory: func() pomoController.PomoControllerIface {
+			ctrl, _ := pomoController.ControllerFactory(
+				pomoController.PomoControllerSessionOpt(sessionFactory),
+				pomoController.PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+					return &pomoTimer.PomoTimer{Clock: clock}
+				}),
+				pomoController.PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+					return pomoSession.DurationFactory(25*time.Minute, 5*time.Minute, 15*time.Minute)
+				}),
+				pomoController.PomoControllerOptionClock(clock),
+			)
+			return ctrl
+		},
+	}
+
+	serv, err := SingleSessionServerFactory(
+		SingleServerContainerOpt(func() *pomoController.SingleControllerContainer {
+			return container
+		}),
+		SingleServerClockOpt(clock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id uint64
+	if err := serv.OpenTicks(time.Second, &id); err != nil {
+		t.Fatal(err)
+	}
+
+	var tick pomoController.PomoControllerTick
+	if err := serv.NextTick(id, &tick); err != nil {
+		t.Fatal(err)
+	}
+	if tick.State != pomoController.PomoControllerStopped {
+		t.Fatalf("Expected stopped tick, got %s", tick.State)
+	}
+
+	var status pomoController.PomoControllerStatus
+	if err := serv.Play(pomoController.SessionLabel{}, &status); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(10 * time.Second)
+	if err := serv.NextTick(id, &tick); err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(tick.TimeLeft) != 25*time.Minute-10*time.Second {
+		t.Fatalf("Expected latest tick with 24m50s left, got %s", time.Duration(tick.TimeLeft))
+	}
+
+	// NOT POLLED FOR TOO LONG.
+	clock.Advance(time.Minute)
+	if err := serv.NextTick(id, &tick); err != ErrTickStreamNotFound {
+		t.Fatalf("Expected abandoned stream to close, got %v", err)
+	}
+
+	if err := serv.OpenTicks(time.Second, &id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := serv.CloseTicks(id, &struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := serv.NextTick(id, &tick); err != ErrTickStreamNotFound {
+		t.Fatalf("Expected ErrTickStreamNotFound, got %v", err)
+	}
+}
+
 // TODO: INCLUDE MORE TESTS. TEST ERRORS AND COMBINATION.
+
+// PENDING POLLS AND ACTIONS END WITH THE ROOT CONTEXT.
+func TestSSRootCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serv, err := SingleSessionServerFactory(
+		SingleServerContainerOpt(ssContainerFactory),
+		SingleServerContextOpt(ctx),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id uint64
+	if err := serv.OpenTicks(time.Second, &id); err != nil {
+		t.Fatal(err)
+	}
+	var tick pomoController.PomoControllerTick
+	// FIRST TICK IS SENT RIGHT AWAY.
+	if err := serv.NextTick(id, &tick); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- serv.NextTick(id, &tick)
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected pending poll to end on cancel")
+	}
+
+	var status pomoController.PomoControllerStatus
+	if err := serv.Play(pomoController.SessionLabel{}, &status); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// LABELLED PLAY LEAVES OVERTIME LIKE A PLAIN ONE.
+func TestSSPlayOvertime(t *testing.T) {
+	timer := new(pomoTimer.MockCbTimer)
+	container := &pomoController.SingleControllerContainer{
+		ControllerFactory: func() pomoController.PomoControllerIface {
+			ctrl, _ := pomoController.ControllerFactory(
+				pomoController.PomoControllerSessionOpt(sessionFactory),
+				pomoController.PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+					return timer
+				}),
+				pomoController.PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+					return pomoSession.DurationFactory(25*time.Minute, 5*time.Minute, 15*time.Minute)
+				}),
+				pomoController.PomoControllerOptionAdvancePolicy(pomoController.PomoControllerAdvancePolicy{
+					ManualAfterWork: true,
+				}),
+			)
+			return ctrl
+		},
+	}
+
+	serv, err := SingleSessionServerFactory(
+		SingleServerContainerOpt(func() *pomoController.SingleControllerContainer {
+			return container
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var status pomoController.PomoControllerStatus
+	if err := serv.Play(pomoController.SessionLabel{}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if err := timer.ForceDone(); err != nil {
+		t.Fatal(err)
+	}
+	if err := serv.Status(struct{}{}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.State != pomoController.PomoControllerOvertime {
+		t.Fatalf("Expected overtime, got %s", status.State)
+	}
+
+	label := pomoController.SessionLabel{Task: "review"}
+	if err := serv.Play(label, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.State != pomoController.PomoControllerShortBreak {
+		t.Fatalf("Expected short break, got %s", status.State)
+	}
+	if status.Label == nil || status.Label.Task != label.Task {
+		t.Fatalf("Expected label %v and got %v", label, status.Label)
+	}
+
+	// RUNNING TIMERS ARE STILL NOT RELABELLED.
+	if err := serv.Play(pomoController.SessionLabel{Task: "other"}, &status); err != pomoController.ErrRunningTimer {
+		t.Fatalf("Expected ErrRunningTimer, got %v", err)
+	}
+	if err := serv.Status(struct{}{}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Label == nil || status.Label.Task != label.Task {
+		t.Fatalf("Expected label %v and got %v", label, status.Label)
+	}
+}
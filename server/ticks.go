@@ -0,0 +1,153 @@
+// Tick streams over rpc. net/rpc has no server push so clients long poll
+// NextTick on the same connection, one call per tick.
+
+package server
+
+import (
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Streams not polled for this long past their interval are closed.
+const tickStreamIdle = 10 * time.Second
+
+type pomoTick = pomoController.PomoControllerTick
+
+// Only the latest tick is kept so slow clients skip ticks instead of blocking
+// the controller.
+type tickStream struct {
+	every    time.Duration
+	ticks    chan pomoTick
+	done     chan struct{}
+	unsub    func()
+	lastPoll time.Time
+	locker   sync.Mutex
+}
+
+func (s *tickStream) push(tick pomoTick) {
+	select {
+	case <-s.ticks:
+	default:
+	}
+	select {
+	case s.ticks <- tick:
+	default:
+	}
+}
+
+func (s *tickStream) idle(now time.Time) bool {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	return now.Sub(s.lastPoll) > 3*s.every+tickStreamIdle
+}
+
+func (s *tickStream) polled(now time.Time) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.lastPoll = now
+}
+
+// Registry of open streams of a server.
+type tickStreams struct {
+	streams map[uint64]*tickStream
+	lastId  uint64
+	locker  sync.Mutex
+}
+
+func (ts *tickStreams) get(id uint64) *tickStream {
+	ts.locker.Lock()
+	defer ts.locker.Unlock()
+	return ts.streams[id]
+}
+
+func (ts *tickStreams) add(s *tickStream) uint64 {
+	ts.locker.Lock()
+	defer ts.locker.Unlock()
+	if ts.streams == nil {
+		ts.streams = make(map[uint64]*tickStream)
+	}
+	ts.lastId++
+	ts.streams[ts.lastId] = s
+	return ts.lastId
+}
+
+func (ts *tickStreams) close(id uint64) error {
+	ts.locker.Lock()
+	s, ok := ts.streams[id]
+	delete(ts.streams, id)
+	ts.locker.Unlock()
+
+	if !ok {
+		return ErrTickStreamNotFound
+	}
+	s.unsub()
+	close(s.done)
+	return nil
+}
+
+// Open a stream of ticks every request interval. Returns the stream id.
+func (c *SingleSessionServer) OpenTicks(
+	request time.Duration,
+	reply *uint64,
+) error {
+	// WATCHING BEFORE THE FIRST PLAY IS FINE.
+	ctrl := c.container.CreateController()
+
+	stream := &tickStream{
+		every:    request,
+		ticks:    make(chan pomoTick, 1),
+		done:     make(chan struct{}),
+		lastPoll: c.now(),
+	}
+
+	// ID IS NEEDED BY THE SINK TO CLOSE ABANDONED STREAMS. ZERO UNTIL ADDED.
+	var id atomic.Uint64
+
+	unsub, err := ctrl.SubscribeTicksCtx(c.context(), request, func(tick pomoTick) {
+		stream.push(tick)
+		if streamId := id.Load(); streamId != 0 && stream.idle(tick.At) {
+			slog.Info("Closing abandoned tick stream", "id", streamId)
+			_ = c.ticks.close(streamId)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	stream.unsub = unsub
+
+	id.Store(c.ticks.add(stream))
+	*reply = id.Load()
+	return nil
+}
+
+// Block until the next tick of the stream.
+func (c *SingleSessionServer) NextTick(
+	request uint64,
+	reply *pomoController.PomoControllerTick,
+) error {
+	stream := c.ticks.get(request)
+	if stream == nil {
+		return ErrTickStreamNotFound
+	}
+	stream.polled(c.now())
+
+	select {
+	case tick := <-stream.ticks:
+		*reply = tick
+		return nil
+	case <-stream.done:
+		return ErrTickStreamNotFound
+	case <-c.context().Done():
+		return c.context().Err()
+	}
+}
+
+func (c *SingleSessionServer) CloseTicks(
+	request uint64,
+	reply *struct{},
+) error {
+	return c.ticks.close(request)
+}
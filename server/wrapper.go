@@ -6,6 +6,7 @@ package server
 import (
 	pomoController "github.com/FernandoAFS/pomogo/controller"
 	"log/slog"
+	"time"
 )
 
 type SessionWrapper struct {
@@ -33,11 +34,11 @@ func (sw *SessionWrapper) Pause(
 }
 
 func (sw *SessionWrapper) Play(
-	request struct{},
+	request pomoController.SessionLabel,
 	reply *pomoController.PomoControllerStatus,
 ) error {
 	slog.Info("Status Request")
-	err := sw.serverSession.Pause(request, reply)
+	err := sw.serverSession.Play(request, reply)
 	slog.Info("Status Response", "reply", reply, "err", err)
 	return err
 }
@@ -61,3 +62,64 @@ func (sw *SessionWrapper) Stop(
 	slog.Info("Status Response", "reply", reply, "err", err)
 	return err
 }
+
+func (sw *SessionWrapper) Label(
+	request pomoController.SessionLabel,
+	reply *pomoController.PomoControllerStatus,
+) error {
+	slog.Info("Label Request", "label", request)
+	err := sw.serverSession.Label(request, reply)
+	slog.Info("Label Response", "reply", reply, "err", err)
+	return err
+}
+
+func (sw *SessionWrapper) Extend(
+	request time.Duration,
+	reply *pomoController.PomoControllerStatus,
+) error {
+	slog.Info("Extend Request", "delta", request)
+	err := sw.serverSession.Extend(request, reply)
+	slog.Info("Extend Response", "reply", reply, "err", err)
+	return err
+}
+
+func (sw *SessionWrapper) Interrupt(
+	request InterruptRequest,
+	reply *pomoController.PomoControllerStatus,
+) error {
+	slog.Info("Interrupt Request", "request", request)
+	err := sw.serverSession.Interrupt(request, reply)
+	slog.Info("Interrupt Response", "reply", reply, "err", err)
+	return err
+}
+
+func (sw *SessionWrapper) OpenTicks(
+	request time.Duration,
+	reply *uint64,
+) error {
+	slog.Info("OpenTicks Request", "every", request)
+	err := sw.serverSession.OpenTicks(request, reply)
+	slog.Info("OpenTicks Response", "reply", *reply, "err", err)
+	return err
+}
+
+// ONE CALL PER TICK. DEBUG LEVEL TO KEEP LOGS READABLE.
+func (sw *SessionWrapper) NextTick(
+	request uint64,
+	reply *pomoController.PomoControllerTick,
+) error {
+	slog.Debug("NextTick Request", "id", request)
+	err := sw.serverSession.NextTick(request, reply)
+	slog.Debug("NextTick Response", "reply", reply, "err", err)
+	return err
+}
+
+func (sw *SessionWrapper) CloseTicks(
+	request uint64,
+	reply *struct{},
+) error {
+	slog.Info("CloseTicks Request", "id", request)
+	err := sw.serverSession.CloseTicks(request, reply)
+	slog.Info("CloseTicks Response", "err", err)
+	return err
+}
@@ -1,5 +1,10 @@
 package session
 
+import (
+	"fmt"
+	"strings"
+)
+
 type PomoSessionStatus int8
 
 const (
@@ -20,3 +25,17 @@ func (s PomoSessionStatus) String() string {
 
 	panic("Impossible session status operator")
 }
+
+// Parse status by name. Short forms are accepted: work or w, short, shortbreak
+// or s, long, longbreak or l.
+func ParseSessionStatus(name string) (PomoSessionStatus, error) {
+	switch strings.ToLower(name) {
+	case "work", "w":
+		return PomoSessionWork, nil
+	case "short", "shortbreak", "s":
+		return PomoSessionShortBreak, nil
+	case "long", "longbreak", "l":
+		return PomoSessionLongBreak, nil
+	}
+	return 0, fmt.Errorf("unknown status %q", name)
+}
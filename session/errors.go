@@ -0,0 +1,8 @@
+package session
+
+import "errors"
+
+var ErrEmptySequence = errors.New("session sequence must have at least one step")
+var ErrInvalidSequenceStep = errors.New("invalid session sequence step")
+var ErrSnapshotMismatch = errors.New("session snapshot does not match session configuration")
+var ErrInvalidFlowtimeTable = errors.New("invalid flowtime break table")
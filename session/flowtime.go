@@ -0,0 +1,105 @@
+// Flowtime: work as long as you want and take a break proportional to it.
+
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Break for work intervals up to the given length.
+type FlowtimeBreakStep struct {
+	UpTo  time.Duration
+	Break time.Duration
+}
+
+// Duration strategy where breaks depend on the time worked right before. Work
+// duration comes from Base and works as a minimum: the controller should wait
+// for the user to move on once it's over. Breaks are taken from Table if set,
+// else they are Ratio times the time worked.
+type FlowtimeDuration struct {
+	Base  SessionStateDurationFactory
+	Ratio float64
+	Table []FlowtimeBreakStep
+
+	worked time.Duration
+	locker sync.Mutex
+}
+
+// Inform time worked in the last work interval.
+func (f *FlowtimeDuration) SetWorked(worked time.Duration) {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+	f.worked = worked
+}
+
+func (f *FlowtimeDuration) breakDuration() time.Duration {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	if len(f.Table) == 0 {
+		return time.Duration(float64(f.worked) * f.Ratio).Round(time.Second)
+	}
+
+	for _, step := range f.Table {
+		if f.worked <= step.UpTo {
+			return step.Break
+		}
+	}
+	return f.Table[len(f.Table)-1].Break
+}
+
+func (f *FlowtimeDuration) GetDurationFactory() SessionStateDurationFactory {
+	return func(s PomoSessionStatus) time.Duration {
+		if s == PomoSessionWork {
+			return f.Base(s)
+		}
+		return f.breakDuration()
+	}
+}
+
+// Parse break table text form. Comma separated pairs of work up to and break
+// duration. Longer work intervals get the last break:
+//
+//	25m=5m,50m=8m,90m=10m,2h=15m
+func ParseFlowtimeTable(text string) ([]FlowtimeBreakStep, error) {
+	steps := []FlowtimeBreakStep{}
+
+	for _, token := range strings.Split(text, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		upToText, breakText, ok := strings.Cut(token, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: missing break in %q", ErrInvalidFlowtimeTable, token)
+		}
+
+		upTo, err := time.ParseDuration(strings.TrimSpace(upToText))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFlowtimeTable, err)
+		}
+		brk, err := time.ParseDuration(strings.TrimSpace(breakText))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFlowtimeTable, err)
+		}
+		if upTo <= 0 || brk < 0 {
+			return nil, fmt.Errorf("%w: invalid step %q", ErrInvalidFlowtimeTable, token)
+		}
+
+		steps = append(steps, FlowtimeBreakStep{UpTo: upTo, Break: brk})
+	}
+
+	if len(steps) == 0 {
+		return nil, ErrInvalidFlowtimeTable
+	}
+
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].UpTo < steps[j].UpTo
+	})
+	return steps, nil
+}
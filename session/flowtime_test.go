@@ -0,0 +1,63 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFlowtimeRatio(t *testing.T) {
+	f := FlowtimeDuration{
+		Base:  DurationFactory(0, 5*time.Minute, 15*time.Minute),
+		Ratio: 0.2,
+	}
+	durationF := f.GetDurationFactory()
+
+	if d := durationF(PomoSessionWork); d != 0 {
+		t.Fatalf("Work duration is %s, expected open ended", d)
+	}
+
+	f.SetWorked(50 * time.Minute)
+	if d := durationF(PomoSessionShortBreak); d != 10*time.Minute {
+		t.Fatalf("Break duration is %s, expected 10m", d)
+	}
+	if d := durationF(PomoSessionLongBreak); d != 10*time.Minute {
+		t.Fatalf("Long break duration is %s, expected 10m", d)
+	}
+}
+
+func TestFlowtimeTable(t *testing.T) {
+	table, err := ParseFlowtimeTable("50m=8m, 25m=5m,90m=10m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := FlowtimeDuration{
+		Base:  DurationFactory(0, 5*time.Minute, 15*time.Minute),
+		Table: table,
+	}
+	durationF := f.GetDurationFactory()
+
+	cases := map[time.Duration]time.Duration{
+		10 * time.Minute:  5 * time.Minute,
+		25 * time.Minute:  5 * time.Minute,
+		40 * time.Minute:  8 * time.Minute,
+		90 * time.Minute:  10 * time.Minute,
+		180 * time.Minute: 10 * time.Minute,
+	}
+
+	for worked, expected := range cases {
+		f.SetWorked(worked)
+		if d := durationF(PomoSessionShortBreak); d != expected {
+			t.Fatalf("Break after %s is %s, expected %s", worked, d, expected)
+		}
+	}
+}
+
+func TestFlowtimeTableParseErrors(t *testing.T) {
+	for _, text := range []string{"", "25m", "25m=x", "0=5m"} {
+		if _, err := ParseFlowtimeTable(text); !errors.Is(err, ErrInvalidFlowtimeTable) {
+			t.Fatalf("Parsing %q returned %v, expected %v", text, err, ErrInvalidFlowtimeTable)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Session that follows an arbitrary, user defined cycle of states instead of
+// the fixed work/short break/long break pattern.
+
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Single step of a sequence. Duration is optional, zero means "use the
+// default duration of the status".
+type SessionSequenceStep struct {
+	Status   PomoSessionStatus
+	Duration time.Duration
+}
+
+// Session driven by an ordered list of steps. Once the last step is done it
+// starts over from the first one.
+type PomoSequenceSession struct {
+	Steps          []SessionSequenceStep
+	index          int // -1 BEFORE THE FIRST STEP IS SET.
+	workedSessions int
+}
+
+// Create a sequence session ready to be played.
+func NewSequenceSession(steps []SessionSequenceStep) (*PomoSequenceSession, error) {
+	if len(steps) == 0 {
+		return nil, ErrEmptySequence
+	}
+	s := &PomoSequenceSession{Steps: steps}
+	s.Reset()
+	return s, nil
+}
+
+func (s *PomoSequenceSession) nextIndex() int {
+	return (s.index + 1) % len(s.Steps)
+}
+
+func (s *PomoSequenceSession) Status() PomoSessionStatus {
+	if s.index < 0 {
+		return s.Steps[0].Status
+	}
+	return s.Steps[s.index].Status
+}
+
+func (s *PomoSequenceSession) CompletedWorkSessions() int {
+	return s.workedSessions
+}
+
+func (s *PomoSequenceSession) GetNextStatus() PomoSessionStatus {
+	return s.Steps[s.nextIndex()].Status
+}
+
+// Move to the next step. If the given status does not match the next step it
+// jumps to the next step with that status.
+func (s *PomoSequenceSession) SetNextStatus(status PomoSessionStatus) {
+	prev := s.Status()
+	started := s.index >= 0
+
+	next := s.nextIndex()
+	for i := 0; i < len(s.Steps); i++ {
+		candidate := (next + i) % len(s.Steps)
+		if s.Steps[candidate].Status == status {
+			next = candidate
+			break
+		}
+	}
+
+	if started && prev != PomoSessionWork && status == PomoSessionWork {
+		s.workedSessions++
+	}
+	s.index = next
+}
+
+func (s *PomoSequenceSession) Reset() {
+	s.index = -1
+	s.workedSessions = 0
+}
+
+// Duration factory that honours per-step durations. The controller asks for
+// the duration of a status right before setting it, so the step looked up is
+// the next one. Falls back to the given factory when the step has no duration.
+func (s *PomoSequenceSession) GetDurationFactory(
+	fallback SessionStateDurationFactory,
+) SessionStateDurationFactory {
+	return func(status PomoSessionStatus) time.Duration {
+		step := s.Steps[s.nextIndex()]
+		if step.Status == status && step.Duration > 0 {
+			return step.Duration
+		}
+		return fallback(status)
+	}
+}
+
+// =======
+// PARSING
+// =======
+
+func parseSequenceStatus(name string) (PomoSessionStatus, error) {
+	status, err := ParseSessionStatus(name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSequenceStep, err)
+	}
+	return status, nil
+}
+
+// Parse compact sequence text form. Steps are comma separated and each one is
+// a status name optionally followed by a duration:
+//
+//	work,short,work,short,work,long
+//	work:52m,short:17m
+func ParseSequence(text string) ([]SessionSequenceStep, error) {
+	steps := []SessionSequenceStep{}
+
+	for _, token := range strings.Split(text, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		name, durationText, hasDuration := strings.Cut(token, ":")
+		status, err := parseSequenceStatus(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		step := SessionSequenceStep{Status: status}
+		if hasDuration {
+			d, err := time.ParseDuration(strings.TrimSpace(durationText))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidSequenceStep, err)
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("%w: non positive duration %q", ErrInvalidSequenceStep, token)
+			}
+			step.Duration = d
+		}
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		return nil, ErrEmptySequence
+	}
+	return steps, nil
+}
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSequenceParse(t *testing.T) {
+	steps, err := ParseSequence("work:52m, short:17m,LONG")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SessionSequenceStep{
+		{Status: PomoSessionWork, Duration: 52 * time.Minute},
+		{Status: PomoSessionShortBreak, Duration: 17 * time.Minute},
+		{Status: PomoSessionLongBreak},
+	}
+
+	if len(steps) != len(expected) {
+		t.Fatalf("Expected %d steps and got %d", len(expected), len(steps))
+	}
+
+	for i := range expected {
+		if steps[i] != expected[i] {
+			t.Fatalf("Step %d is %v, expected %v", i, steps[i], expected[i])
+		}
+	}
+}
+
+func TestSequenceParseErrors(t *testing.T) {
+	cases := map[string]error{
+		"":             ErrEmptySequence,
+		" , ":          ErrEmptySequence,
+		"work,nap":     ErrInvalidSequenceStep,
+		"work:forever": ErrInvalidSequenceStep,
+		"work:-5m":     ErrInvalidSequenceStep,
+	}
+
+	for text, expectedErr := range cases {
+		if _, err := ParseSequence(text); !errors.Is(err, expectedErr) {
+			t.Fatalf("Parsing %q returned %v, expected %v", text, err, expectedErr)
+		}
+	}
+}
+
+func TestSequenceLoop(t *testing.T) {
+	steps, err := ParseSequence("work,short,work,short,work,long,work,long")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSequenceSession(steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SAME CALL ORDER AS THE CONTROLLER: SET CURRENT STATUS ON PLAY.
+	s.SetNextStatus(s.Status())
+
+	N_ITERATIONS := 50
+	expectedNWorks := 0
+	for i := 0; i < N_ITERATIONS; i++ {
+		expectedSt := steps[i%len(steps)].Status
+		if st := s.Status(); st != expectedSt {
+			t.Fatal("iteration: ", i, "status: ", st, "expected: ", expectedSt)
+		}
+
+		nextSt := s.GetNextStatus()
+		if nextSt == PomoSessionWork {
+			expectedNWorks++
+		}
+		s.SetNextStatus(nextSt)
+
+		if n := s.CompletedWorkSessions(); n != expectedNWorks {
+			t.Fatal("iteration: ", i, "worked sessions: ", n, "expected: ", expectedNWorks)
+		}
+	}
+}
+
+func TestSequenceStepDuration(t *testing.T) {
+	steps, err := ParseSequence("work:52m,short:17m,work,long")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSequenceSession(steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fallback := DurationFactory(25*time.Minute, 5*time.Minute, 15*time.Minute)
+	durationF := s.GetDurationFactory(fallback)
+
+	expected := []time.Duration{
+		52 * time.Minute,
+		17 * time.Minute,
+		25 * time.Minute,
+		15 * time.Minute,
+		52 * time.Minute,
+	}
+
+	status := s.Status()
+	for i, d := range expected {
+		if got := durationF(status); got != d {
+			t.Fatalf("Step %d duration is %s, expected %s", i, got, d)
+		}
+		s.SetNextStatus(status)
+		status = s.GetNextStatus()
+	}
+}
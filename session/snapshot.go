@@ -0,0 +1,50 @@
+// Serializable session state to survive server restarts.
+
+package session
+
+type SessionSnapshot struct {
+	Status         PomoSessionStatus
+	WorkedSessions int
+	Index          int // ONLY USED BY SEQUENCE SESSIONS.
+}
+
+// Sessions that may be saved and restored.
+type PomoSessionSnapshotIface interface {
+	Snapshot() SessionSnapshot
+	Restore(snapshot SessionSnapshot) error
+}
+
+func (s *PomoSession) Snapshot() SessionSnapshot {
+	return SessionSnapshot{
+		Status:         s.status,
+		WorkedSessions: s.workedSessions,
+	}
+}
+
+func (s *PomoSession) Restore(snapshot SessionSnapshot) error {
+	s.status = snapshot.Status
+	s.workedSessions = snapshot.WorkedSessions
+	return nil
+}
+
+func (s *PomoSequenceSession) Snapshot() SessionSnapshot {
+	return SessionSnapshot{
+		Status:         s.Status(),
+		WorkedSessions: s.workedSessions,
+		Index:          s.index,
+	}
+}
+
+// Fails if the snapshot does not fit the sequence. It may happen if the
+// sequence was changed between restarts.
+func (s *PomoSequenceSession) Restore(snapshot SessionSnapshot) error {
+	if snapshot.Index < -1 || snapshot.Index >= len(s.Steps) {
+		return ErrSnapshotMismatch
+	}
+	if snapshot.Index >= 0 && s.Steps[snapshot.Index].Status != snapshot.Status {
+		return ErrSnapshotMismatch
+	}
+	s.index = snapshot.Index
+	s.workedSessions = snapshot.WorkedSessions
+	return nil
+}
@@ -0,0 +1,7 @@
+package simulation
+
+import "errors"
+
+var ErrUnknownAction = errors.New("unknown simulation action")
+var ErrMissingArgument = errors.New("missing simulation action argument")
+var ErrTimeTravel = errors.New("simulation steps must be in time order")
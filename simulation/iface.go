@@ -0,0 +1,98 @@
+// Scenario description and simulation results.
+
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+	"time"
+)
+
+type pomoStatus = pomoController.PomoControllerStatus
+
+// Duration readable as json text like "25m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Controller configuration. Zero values take the server defaults.
+type Config struct {
+	Work              Duration
+	ShortBreak        Duration
+	LongBreak         Duration
+	WorkSessions      int
+	Sequence          string
+	ManualAfterWork   bool
+	ManualAfterBreak  bool
+	VoidInterruptions int
+	Warnings          string
+}
+
+// Assertions on status. Nil fields are not checked.
+type Expect struct {
+	State          *pomoController.PomoControllerState
+	TimeLeft       *Duration
+	Overtime       *Duration
+	WorkedSessions *int
+}
+
+// Action or assertion at some time from the start of the simulation. Actions
+// are play, pause, skip, stop, extend <duration>, interrupt <kind> [note] and
+// label <task> [project].
+type Step struct {
+	At     Duration
+	Action string
+	Args   []string
+	// Expected error text of the action. Empty if it must succeed.
+	Error  string
+	Expect *Expect
+}
+
+// Scripted simulation checked against the expected event log.
+type Scenario struct {
+	Name   string
+	Config Config
+	Steps  []Step
+	// Events as "<time from start> <event type> <state>", e.g. "25m0s NextState Work".
+	Events []string
+}
+
+// Event seen by the simulation. At is the time from the start.
+type Event struct {
+	At    time.Duration
+	Type  pomoController.PomoControllerEventType
+	State pomoController.PomoControllerState
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s %s", e.At, e.Type, e.State)
+}
+
+// Status after a step.
+type TimelineEntry struct {
+	At     time.Duration
+	Status pomoController.PomoControllerStatus
+}
+
+type Result struct {
+	Events   []Event
+	Timeline []TimelineEntry
+	// Mismatches with the scenario. Empty if it passed.
+	Failures []string
+}
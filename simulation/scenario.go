@@ -0,0 +1,147 @@
+// Scenario files and runner.
+
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Read json scenario file.
+func LoadScenario(path string) (Scenario, error) {
+	var scenario Scenario
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return scenario, err
+	}
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return scenario, fmt.Errorf("%s: %w", path, err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return scenario, nil
+}
+
+// Read every json scenario in dir sorted by file name.
+func LoadScenarios(dir string) ([]Scenario, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	scenarios := make([]Scenario, 0, len(paths))
+	for _, path := range paths {
+		scenario, err := LoadScenario(path)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+// Run every step and compare with the scenario. Mismatches are reported in
+// the result. Errors are only returned for broken scenarios.
+func Run(scenario Scenario) (*Result, error) {
+	sim, err := New(scenario.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	fail := func(format string, args ...any) {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+	}
+
+	for i, step := range scenario.Steps {
+		at := time.Duration(step.At)
+		if err := sim.AdvanceTo(at); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+
+		if step.Action != "" {
+			err := sim.Do(step.Action, step.Args...)
+			switch {
+			case err != nil && step.Error == "":
+				fail("step %d at %s: %s failed: %s", i, at, step.Action, err)
+			case err == nil && step.Error != "":
+				fail("step %d at %s: %s expected error %q", i, at, step.Action, step.Error)
+			case err != nil && !strings.Contains(err.Error(), step.Error):
+				fail("step %d at %s: %s expected error %q, got %q", i, at, step.Action, step.Error, err)
+			}
+		}
+
+		status := sim.Controller.Status()
+		result.Timeline = append(result.Timeline, TimelineEntry{At: at, Status: status})
+
+		if step.Expect != nil {
+			for _, mismatch := range step.Expect.check(status) {
+				fail("step %d at %s: %s", i, at, mismatch)
+			}
+		}
+	}
+
+	result.Events = sim.Events()
+	if scenario.Events != nil {
+		got := make([]string, len(result.Events))
+		for i, e := range result.Events {
+			got[i] = e.String()
+		}
+		if strings.Join(got, "\n") != strings.Join(scenario.Events, "\n") {
+			fail("events:\nexpected:\n\t%s\ngot:\n\t%s",
+				strings.Join(scenario.Events, "\n\t"),
+				strings.Join(got, "\n\t"),
+			)
+		}
+	}
+
+	return result, nil
+}
+
+func (e *Expect) check(status pomoStatus) []string {
+	mismatches := []string{}
+
+	if e.State != nil && *e.State != status.State {
+		mismatches = append(mismatches, fmt.Sprintf("expected state %s, got %s", *e.State, status.State))
+	}
+
+	if e.TimeLeft != nil {
+		switch {
+		case status.TimeLeft == nil:
+			mismatches = append(mismatches, fmt.Sprintf("expected %s left, got none", time.Duration(*e.TimeLeft)))
+		case time.Duration(*status.TimeLeft) != time.Duration(*e.TimeLeft):
+			mismatches = append(mismatches, fmt.Sprintf(
+				"expected %s left, got %s",
+				time.Duration(*e.TimeLeft),
+				time.Duration(*status.TimeLeft),
+			))
+		}
+	}
+
+	if e.Overtime != nil {
+		switch {
+		case status.Overtime == nil:
+			mismatches = append(mismatches, fmt.Sprintf("expected %s overtime, got none", time.Duration(*e.Overtime)))
+		case time.Duration(*status.Overtime) != time.Duration(*e.Overtime):
+			mismatches = append(mismatches, fmt.Sprintf(
+				"expected %s overtime, got %s",
+				time.Duration(*e.Overtime),
+				time.Duration(*status.Overtime),
+			))
+		}
+	}
+
+	if e.WorkedSessions != nil && *e.WorkedSessions != status.WorkedSessions {
+		mismatches = append(mismatches, fmt.Sprintf(
+			"expected %d worked sessions, got %d",
+			*e.WorkedSessions,
+			status.WorkedSessions,
+		))
+	}
+
+	return mismatches
+}
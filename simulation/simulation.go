@@ -0,0 +1,217 @@
+// Drive a controller on a fake clock. A day of sessions runs in milliseconds
+// and the same script always gives the same events.
+
+package simulation
+
+import (
+	"fmt"
+	pomoController "github.com/FernandoAFS/pomogo/controller"
+	pomoSession "github.com/FernandoAFS/pomogo/session"
+	pomoTimer "github.com/FernandoAFS/pomogo/timer"
+	"sync"
+	"time"
+)
+
+// Default start of every simulation. Any fixed time works.
+var DefaultStart = time.Date(2024, 12, 04, 9, 0, 0, 0, time.UTC)
+
+type Simulation struct {
+	Clock      *pomoTimer.FakeClock
+	Controller *pomoController.PomoController
+	Start      time.Time
+
+	events []Event
+	locker sync.Mutex
+}
+
+func withDefault(d Duration, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return time.Duration(d)
+}
+
+// Create simulation starting at DefaultStart.
+func New(config Config) (*Simulation, error) {
+	clock := pomoTimer.NewFakeClock(DefaultStart)
+	s := &Simulation{
+		Clock: clock,
+		Start: DefaultStart,
+	}
+
+	durationF := pomoSession.DurationFactory(
+		withDefault(config.Work, 25*time.Minute),
+		withDefault(config.ShortBreak, 5*time.Minute),
+		withDefault(config.LongBreak, 15*time.Minute),
+	)
+
+	workSessions := config.WorkSessions
+	if workSessions == 0 {
+		workSessions = 4
+	}
+
+	var session pomoSession.PomoSessionIface = &pomoSession.PomoSession{
+		WorkSessionsBreak: workSessions,
+	}
+	if config.Sequence != "" {
+		steps, err := pomoSession.ParseSequence(config.Sequence)
+		if err != nil {
+			return nil, err
+		}
+		seq, err := pomoSession.NewSequenceSession(steps)
+		if err != nil {
+			return nil, err
+		}
+		session = seq
+		durationF = seq.GetDurationFactory(durationF)
+	}
+
+	warnings, err := pomoController.ParseWarnings(config.Warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl, err := pomoController.ControllerFactory(
+		pomoController.PomoControllerSessionOpt(func() pomoSession.PomoSessionIface {
+			return session
+		}),
+		pomoController.PomoControllerTimerOpt(func() pomoTimer.PomoTimerIface {
+			return &pomoTimer.PomoTimer{Clock: clock}
+		}),
+		pomoController.PomoControllerDurationF(func() pomoSession.SessionStateDurationFactory {
+			return durationF
+		}),
+		pomoController.PomoControllerOptionClock(clock),
+		pomoController.PomoControllerOptionAdvancePolicy(pomoController.PomoControllerAdvancePolicy{
+			ManualAfterWork:  config.ManualAfterWork,
+			ManualAfterBreak: config.ManualAfterBreak,
+		}),
+		pomoController.PomoControllerOptionVoidThreshold(config.VoidInterruptions),
+		pomoController.PomoControllerOptionWarnings(warnings),
+		pomoController.PomoControllerOptionListener(s.listener()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.Controller = ctrl
+	return s, nil
+}
+
+// Time from the start.
+func (s *Simulation) Elapsed() time.Duration {
+	return s.Clock.Now().Sub(s.Start)
+}
+
+func (s *Simulation) record(
+	at time.Time,
+	eventType pomoController.PomoControllerEventType,
+	state pomoController.PomoControllerState,
+) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.events = append(s.events, Event{
+		At:    at.Sub(s.Start),
+		Type:  eventType,
+		State: state,
+	})
+}
+
+func (s *Simulation) listener() pomoController.PomoControllerListener {
+	return pomoController.PomoControllerListener{
+		Play: func(e pomoController.PomoControllerEventArgsPlay) {
+			s.record(e.At, pomoController.PomoControllerEventTypePlay, e.CurrentState)
+		},
+		Stop: func(e pomoController.PomoControllerEventArgsStop) {
+			s.record(e.At, pomoController.PomoControllerEventTypeStop, e.CurrentState)
+		},
+		Pause: func(e pomoController.PomoControllerEventArgsPause) {
+			s.record(e.At, pomoController.PomoControllerEventTypePause, e.CurrentState)
+		},
+		NextState: func(e pomoController.PomoControllerEventArgsNextState) {
+			s.record(e.At, pomoController.PomoControllerEventTypeNextState, e.CurrentState)
+		},
+		Label: func(e pomoController.PomoControllerEventArgsLabel) {
+			s.record(e.At, pomoController.PomoControllerEventTypeLabel, e.CurrentState)
+		},
+		Extend: func(e pomoController.PomoControllerEventArgsExtend) {
+			s.record(e.At, pomoController.PomoControllerEventTypeExtend, e.CurrentState)
+		},
+		Overtime: func(e pomoController.PomoControllerEventArgsOvertime) {
+			s.record(e.At, pomoController.PomoControllerEventTypeOvertime, e.CurrentState)
+		},
+		Interrupt: func(e pomoController.PomoControllerEventArgsInterrupt) {
+			s.record(e.At, pomoController.PomoControllerEventTypeInterrupt, e.CurrentState)
+		},
+		Goal: func(e pomoController.PomoControllerEventArgsGoal) {
+			s.record(e.At, pomoController.PomoControllerEventTypeGoal, pomoController.PomoControllerWork)
+		},
+		Warning: func(e pomoController.PomoControllerEventArgsWarning) {
+			s.record(e.At, pomoController.PomoControllerEventTypeWarning, e.CurrentState)
+		},
+	}
+}
+
+// Events so far.
+func (s *Simulation) Events() []Event {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	return append([]Event{}, s.events...)
+}
+
+// Move the clock to some time from the start firing every timer on the way.
+func (s *Simulation) AdvanceTo(at time.Duration) error {
+	if at < s.Elapsed() {
+		return fmt.Errorf("%w: %s after %s", ErrTimeTravel, at, s.Elapsed())
+	}
+	s.Clock.AdvanceTo(s.Start.Add(at))
+	return nil
+}
+
+// Run controller action now.
+func (s *Simulation) Do(action string, args ...string) error {
+	now := s.Clock.Now()
+	ctrl := s.Controller
+
+	switch action {
+	case "play":
+		return ctrl.Play(now)
+	case "pause":
+		return ctrl.Pause(now)
+	case "skip":
+		return ctrl.Skip(now)
+	case "stop":
+		return ctrl.Stop(now)
+	case "extend":
+		if len(args) < 1 {
+			return fmt.Errorf("%w: extend <duration>", ErrMissingArgument)
+		}
+		delta, err := time.ParseDuration(args[0])
+		if err != nil {
+			return err
+		}
+		return ctrl.Extend(now, delta)
+	case "interrupt":
+		if len(args) < 1 {
+			return fmt.Errorf("%w: interrupt <kind> [note]", ErrMissingArgument)
+		}
+		kind, err := pomoController.ParseInterruptionKind(args[0])
+		if err != nil {
+			return err
+		}
+		note := ""
+		if len(args) > 1 {
+			note = args[1]
+		}
+		return ctrl.Interrupt(now, kind, note)
+	case "label":
+		if len(args) < 1 {
+			return fmt.Errorf("%w: label <task> [project]", ErrMissingArgument)
+		}
+		label := pomoController.SessionLabel{Task: args[0]}
+		if len(args) > 1 {
+			label.Project = args[1]
+		}
+		return ctrl.Label(now, label)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownAction, action)
+}
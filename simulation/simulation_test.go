@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+)
+
+// =====
+// TESTS
+// =====
+
+// EVERY SCENARIO FILE IN TESTDATA.
+func TestScenarios(t *testing.T) {
+	scenarios, err := LoadScenarios("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) == 0 {
+		t.Fatalf("Expected scenario files in testdata")
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			result, err := Run(scenario)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, failure := range result.Failures {
+				t.Error(failure)
+			}
+		})
+	}
+}
+
+// A WRONG EXPECTATION IS REPORTED, NOT IGNORED.
+func TestScenarioFailure(t *testing.T) {
+	scenario := Scenario{
+		Steps: []Step{
+			{At: 0, Action: "play"},
+			{At: Duration(time.Minute), Action: "dance"},
+		},
+		Events: []string{"0s Play ShortBreak"},
+	}
+
+	result, err := Run(scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Failures) != 2 {
+		t.Fatalf("Expected action and events failures, got %v", result.Failures)
+	}
+	if len(result.Timeline) != 2 {
+		t.Fatalf("Expected status after every step, got %d", len(result.Timeline))
+	}
+}
+
+func TestScenarioTimeTravel(t *testing.T) {
+	scenario := Scenario{
+		Steps: []Step{
+			{At: Duration(time.Minute), Action: "play"},
+			{At: 0, Action: "stop"},
+		},
+	}
+	if _, err := Run(scenario); err == nil {
+		t.Fatalf("Expected error on unordered steps")
+	}
+}
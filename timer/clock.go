@@ -0,0 +1,199 @@
+// Time source abstraction. Lets timers and controller run on a fake clock.
+
+package timer
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) ClockTimer
+	AfterFunc(d time.Duration, f func()) ClockTimer
+}
+
+// Subset of time.Timer. C is nil for AfterFunc timers.
+type ClockTimer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Return real clock if none is given.
+func ClockOrReal(c Clock) Clock {
+	if c == nil {
+		return RealClock{}
+	}
+	return c
+}
+
+// ==========
+// REAL CLOCK
+// ==========
+
+type RealClock struct{}
+
+type realClockTimer struct {
+	timer *time.Timer
+}
+
+func (t realClockTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realClockTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(d time.Duration) ClockTimer {
+	return realClockTimer{timer: time.NewTimer(d)}
+}
+
+func (RealClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return realClockTimer{timer: time.AfterFunc(d, f)}
+}
+
+// ==========
+// FAKE CLOCK
+// ==========
+
+// Clock that only moves on Advance. AfterFunc callbacks run synchronously on
+// the goroutine calling Advance, in deadline order, so a whole day can be
+// simulated deterministically. Callbacks may create new timers.
+type FakeClock struct {
+	now     time.Time
+	waiters []*fakeClockTimer
+	locker  sync.Mutex
+}
+
+type fakeClockTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	ch       chan time.Time
+	f        func()
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) add(d time.Duration, ch chan time.Time, f func()) *fakeClockTimer {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	t := &fakeClockTimer{
+		clock:    c,
+		deadline: c.now.Add(d),
+		ch:       ch,
+		f:        f,
+	}
+	c.waiters = append(c.waiters, t)
+	// STABLE TO KEEP CREATION ORDER ON SAME DEADLINE.
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+	})
+	return t
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) ClockTimer {
+	return c.add(d, make(chan time.Time, 1), nil)
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return c.add(d, nil, f)
+}
+
+// Number of pending timers.
+func (c *FakeClock) Waiters() int {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return len(c.waiters)
+}
+
+// Deadline of the next timer. False if there is none.
+func (c *FakeClock) NextDeadline() (time.Time, bool) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if len(c.waiters) == 0 {
+		return time.Time{}, false
+	}
+	return c.waiters[0].deadline, true
+}
+
+// Pop next timer due before target. Moves time to its deadline.
+func (c *FakeClock) popDue(target time.Time) *fakeClockTimer {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	if len(c.waiters) == 0 || c.waiters[0].deadline.After(target) {
+		c.now = target
+		return nil
+	}
+
+	t := c.waiters[0]
+	c.waiters = c.waiters[1:]
+	if t.deadline.After(c.now) {
+		c.now = t.deadline
+	}
+	return t
+}
+
+// Move time forward firing every timer due on the way.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.AdvanceTo(c.Now().Add(d))
+}
+
+func (c *FakeClock) AdvanceTo(target time.Time) {
+	for t := c.popDue(target); t != nil; t = c.popDue(target) {
+		if t.f != nil {
+			t.f()
+			continue
+		}
+		select {
+		case t.ch <- t.deadline:
+		default:
+		}
+	}
+}
+
+// Move time forward without firing timers, as a system suspend does to the
+// wall clock. Pending timers are delayed by the same amount since they follow
+// the monotonic clock.
+func (c *FakeClock) Jump(d time.Duration) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, w := range c.waiters {
+		w.deadline = w.deadline.Add(d)
+	}
+}
+
+func (t *fakeClockTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeClockTimer) Stop() bool {
+	c := t.clock
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	for i, w := range c.waiters {
+		if w == t {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
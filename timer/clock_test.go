@@ -0,0 +1,114 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+var fakeClockRefNow = time.Date(2024, 12, 04, 0, 0, 0, 0, time.UTC)
+
+// =====
+// TESTS
+// =====
+
+// CALLBACKS FIRE IN DEADLINE ORDER AND ONLY WHEN DUE.
+func TestFakeClockAfterFuncOrder(t *testing.T) {
+	clock := NewFakeClock(fakeClockRefNow)
+
+	fired := []int{}
+	clock.AfterFunc(2*time.Minute, func() { fired = append(fired, 2) })
+	clock.AfterFunc(1*time.Minute, func() { fired = append(fired, 1) })
+	stopped := clock.AfterFunc(90*time.Second, func() { fired = append(fired, -1) })
+
+	if !stopped.Stop() {
+		t.Fatalf("Expected pending timer to stop")
+	}
+
+	clock.Advance(30 * time.Second)
+	if len(fired) != 0 {
+		t.Fatalf("Expected no callback, got %v", fired)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if len(fired) != 2 || fired[0] != 1 || fired[1] != 2 {
+		t.Fatalf("Expected callbacks [1 2], got %v", fired)
+	}
+
+	if now := clock.Now(); !now.Equal(fakeClockRefNow.Add(150 * time.Second)) {
+		t.Fatalf("Unexpected clock time %v", now)
+	}
+}
+
+// TIMERS CREATED FROM A CALLBACK FIRE IN THE SAME ADVANCE IF DUE.
+func TestFakeClockChainedTimers(t *testing.T) {
+	clock := NewFakeClock(fakeClockRefNow)
+
+	count := 0
+	var chain func()
+	chain = func() {
+		count++
+		clock.AfterFunc(time.Minute, chain)
+	}
+	clock.AfterFunc(time.Minute, chain)
+
+	clock.Advance(time.Hour)
+	if count != 60 {
+		t.Fatalf("Expected 60 callbacks, got %d", count)
+	}
+	if clock.Waiters() != 1 {
+		t.Fatalf("Expected one pending timer, got %d", clock.Waiters())
+	}
+}
+
+func TestFakeClockNewTimer(t *testing.T) {
+	clock := NewFakeClock(fakeClockRefNow)
+	timer := clock.NewTimer(time.Minute)
+
+	clock.Advance(time.Minute)
+
+	select {
+	case at := <-timer.C():
+		if !at.Equal(fakeClockRefNow.Add(time.Minute)) {
+			t.Fatalf("Unexpected timer time %v", at)
+		}
+	default:
+		t.Fatalf("Expected timer to fire")
+	}
+}
+
+// CANCELLED WAIT NEVER RUNS ITS CALLBACK.
+func TestPomoTimerFakeClock(t *testing.T) {
+	clock := NewFakeClock(fakeClockRefNow)
+	timer := PomoTimer{Clock: clock}
+
+	fired := 0
+	if err := timer.WaitCb(time.Minute, func() { fired++ }); err != nil {
+		t.Fatal(err)
+	}
+	if err := timer.WaitCb(time.Minute, func() { fired++ }); err != ErrTimerWaited {
+		t.Fatalf("Expected ErrTimerWaited, got %v", err)
+	}
+	if err := timer.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Hour)
+	if fired != 0 {
+		t.Fatalf("Expected no callback after cancel, got %d", fired)
+	}
+
+	if err := timer.WaitCb(time.Minute, func() { fired++ }); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+	if fired != 1 {
+		t.Fatalf("Expected one callback, got %d", fired)
+	}
+	if err := timer.Cancel(); err != ErrTimerNotWaited {
+		t.Fatalf("Expected ErrTimerNotWaited, got %v", err)
+	}
+}
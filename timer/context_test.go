@@ -0,0 +1,141 @@
+package timer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+// Context cancellation releases the wait on its own goroutine.
+func waitReleased(t *testing.T, pending func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for pending() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected wait to be released on context cancel")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type ctxTimerCase struct {
+	timer   PomoCtxTimerIface
+	pending func() bool
+	clock   *FakeClock
+	// Callbacks on their own goroutine.
+	async bool
+}
+
+func ctxTimerCases(t *testing.T) map[string]func() ctxTimerCase {
+	return map[string]func() ctxTimerCase{
+		"pomo": func() ctxTimerCase {
+			clock := NewFakeClock(fakeClockRefNow)
+			timer := &PomoTimer{Clock: clock}
+			return ctxTimerCase{
+				timer: timer,
+				clock: clock,
+				pending: func() bool {
+					timer.locker.Lock()
+					defer timer.locker.Unlock()
+					return timer.pending != nil
+				},
+			}
+		},
+		"wall": func() ctxTimerCase {
+			clock := NewFakeClock(fakeClockRefNow)
+			timer := &WallTimer{Clock: clock}
+			return ctxTimerCase{
+				timer: timer,
+				clock: clock,
+				pending: func() bool {
+					timer.locker.Lock()
+					defer timer.locker.Unlock()
+					return timer.pending != nil
+				},
+			}
+		},
+		"scheduler": func() ctxTimerCase {
+			clock := NewFakeClock(fakeClockRefNow)
+			scheduler := &Scheduler{Clock: clock}
+			t.Cleanup(scheduler.Close)
+			return ctxTimerCase{
+				timer:   scheduler.NewTimer(),
+				clock:   clock,
+				pending: func() bool { return scheduler.Pending() > 0 },
+				async:   true,
+			}
+		},
+	}
+}
+
+// =====
+// TESTS
+// =====
+
+func TestWaitCtxCancel(t *testing.T) {
+	for name, factory := range ctxTimerCases(t) {
+		t.Run(name, func(t *testing.T) {
+			tc := factory()
+			fired := make(chan int, 2)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if err := tc.timer.WaitCtx(ctx, time.Minute, func() { fired <- 1 }); err != nil {
+				t.Fatal(err)
+			}
+			cancel()
+			waitReleased(t, tc.pending)
+
+			tc.clock.Advance(time.Hour)
+			if tc.async {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if len(fired) != 0 {
+				t.Fatalf("Expected no callback after context cancel")
+			}
+			if err := tc.timer.Cancel(); err != ErrTimerNotWaited {
+				t.Fatalf("Expected ErrTimerNotWaited, got %v", err)
+			}
+
+			// DONE CONTEXT REFUSES TO WAIT.
+			if err := tc.timer.WaitCtx(ctx, time.Minute, func() { fired <- 1 }); err != context.Canceled {
+				t.Fatalf("Expected context.Canceled, got %v", err)
+			}
+		})
+	}
+}
+
+// CANCELLING THE CONTEXT OF A FINISHED WAIT LEAVES THE NEXT ONE ALONE.
+func TestWaitCtxStale(t *testing.T) {
+	for name, factory := range ctxTimerCases(t) {
+		t.Run(name, func(t *testing.T) {
+			tc := factory()
+			fired := make(chan int, 2)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if err := tc.timer.WaitCtx(ctx, time.Minute, func() { fired <- 1 }); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.timer.Cancel(); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.timer.WaitCb(time.Minute, func() { fired <- 2 }); err != nil {
+				t.Fatal(err)
+			}
+			cancel()
+			time.Sleep(10 * time.Millisecond)
+
+			tc.clock.Advance(time.Minute)
+			select {
+			case v := <-fired:
+				if v != 2 {
+					t.Fatalf("Expected second callback, got %d", v)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Expected second wait to survive the first context")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package timer
 
 import (
+	"context"
 	"time"
 )
 
@@ -8,3 +9,29 @@ type PomoTimerIface interface {
 	WaitCb(d time.Duration, cb func()) error
 	Cancel() error
 }
+
+// Timer whose waits end along with a context, so nothing is left running on
+// shutdown. Waiting on a done context fails with its error.
+type PomoCtxTimerIface interface {
+	PomoTimerIface
+	WaitCtx(ctx context.Context, d time.Duration, cb func()) error
+}
+
+// Clock jump noticed while waiting, like a system suspend. From is the last
+// check before the jump and To the first one after.
+type TimerJump struct {
+	From time.Time
+	To   time.Time
+}
+
+// Time missing between both checks.
+func (j TimerJump) Duration() time.Duration {
+	return j.To.Sub(j.From)
+}
+
+// Timer able to notice clock jumps. On a jump the wait is over and onJump is
+// called instead of cb so the caller decides what to do.
+type PomoJumpTimerIface interface {
+	PomoTimerIface
+	WaitJumpCb(d time.Duration, cb func(), onJump func(jump TimerJump)) error
+}
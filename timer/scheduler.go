@@ -0,0 +1,236 @@
+package timer
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// SINGLE GOROUTINE MULTIPLEXING EVERY PENDING DEADLINE.
+// Deadlines are kept in a min-heap and the goroutine sleeps until the earliest
+// one. Timers from NewTimer share it, so thousands of sessions cost one
+// goroutine and one clock timer. Callbacks run on their own goroutine so a
+// slow one never delays the others.
+type Scheduler struct {
+	Clock Clock
+
+	entries scheduleHeap
+	wake    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	locker  sync.Mutex
+}
+
+type scheduleEntry struct {
+	deadline time.Time
+	cb       func()
+	timer    *SchedulerTimer
+	// Releases the context of the wait.
+	stopCtx func() bool
+	// Position in heap. Needed to remove on cancel.
+	index int
+}
+
+// Timer backed by a scheduler. Same semantics as PomoTimer.
+type SchedulerTimer struct {
+	scheduler *Scheduler
+	// Guarded by scheduler lock.
+	entry *scheduleEntry
+}
+
+// -----------
+// HEAP ORDER
+// -----------
+
+type scheduleHeap []*scheduleEntry
+
+func (h scheduleHeap) Len() int {
+	return len(h)
+}
+
+func (h scheduleHeap) Less(i, j int) bool {
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h scheduleHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *scheduleHeap) Push(x any) {
+	e := x.(*scheduleEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *scheduleHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
+
+// ---------
+// SCHEDULER
+// ---------
+
+// Timer sharing the scheduler goroutine.
+func (s *Scheduler) NewTimer() *SchedulerTimer {
+	return &SchedulerTimer{scheduler: s}
+}
+
+// Number of pending deadlines.
+func (s *Scheduler) Pending() int {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	return len(s.entries)
+}
+
+// Stop the scheduler goroutine. Pending callbacks never run.
+func (s *Scheduler) Close() {
+	s.start()
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
+// Goroutine starts on first use.
+func (s *Scheduler) start() {
+	s.once.Do(func() {
+		s.wake = make(chan struct{}, 1)
+		s.done = make(chan struct{})
+		go s.run()
+	})
+}
+
+func (s *Scheduler) add(ctx context.Context, t *SchedulerTimer, d time.Duration, cb func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.start()
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	// CANNOT WAIT TWICE.
+	if t.entry != nil {
+		return ErrTimerWaited
+	}
+
+	e := &scheduleEntry{
+		deadline: ClockOrReal(s.Clock).Now().Add(d),
+		cb:       cb,
+		timer:    t,
+	}
+	heap.Push(&s.entries, e)
+	t.entry = e
+	e.stopCtx = context.AfterFunc(ctx, func() {
+		s.locker.Lock()
+		defer s.locker.Unlock()
+		if t.entry == e {
+			s.drop(e)
+		}
+	})
+
+	// NEW EARLIEST DEADLINE. THE GOROUTINE MAY BE SLEEPING FOR LONGER.
+	if e.index == 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) remove(t *SchedulerTimer) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if t.entry == nil {
+		return ErrTimerNotWaited
+	}
+
+	s.drop(t.entry)
+	return nil
+}
+
+// Remove pending entry. Call with lock.
+func (s *Scheduler) drop(e *scheduleEntry) {
+	heap.Remove(&s.entries, e.index)
+	e.timer.entry = nil
+	e.stopCtx()
+}
+
+// Pop every entry due at now. Call with lock.
+func (s *Scheduler) popDue(now time.Time) []*scheduleEntry {
+	due := []*scheduleEntry{}
+	for len(s.entries) > 0 && !s.entries[0].deadline.After(now) {
+		e := heap.Pop(&s.entries).(*scheduleEntry)
+		e.timer.entry = nil
+		e.stopCtx()
+		due = append(due, e)
+	}
+	return due
+}
+
+func (s *Scheduler) run() {
+	clock := ClockOrReal(s.Clock)
+
+	for {
+		s.locker.Lock()
+		now := clock.Now()
+		due := s.popDue(now)
+
+		var sleep ClockTimer
+		var sleepC <-chan time.Time
+		if len(s.entries) > 0 {
+			sleep = clock.NewTimer(s.entries[0].deadline.Sub(now))
+			sleepC = sleep.C()
+		}
+		s.locker.Unlock()
+
+		for _, e := range due {
+			go e.cb()
+		}
+
+		select {
+		case <-sleepC:
+		case <-s.wake:
+		case <-s.done:
+			if sleep != nil {
+				sleep.Stop()
+			}
+			return
+		}
+
+		if sleep != nil {
+			sleep.Stop()
+		}
+	}
+}
+
+// ---------------
+// SCHEDULER TIMER
+// ---------------
+
+func (t *SchedulerTimer) WaitCb(d time.Duration, cb func()) error {
+	return t.scheduler.add(context.Background(), t, d, cb)
+}
+
+// Wait cancelled along with ctx. The callback never runs once ctx is done.
+func (t *SchedulerTimer) WaitCtx(ctx context.Context, d time.Duration, cb func()) error {
+	return t.scheduler.add(ctx, t, d, cb)
+}
+
+func (t *SchedulerTimer) Cancel() error {
+	return t.scheduler.remove(t)
+}
@@ -0,0 +1,199 @@
+package timer
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+// Wait for callback or fail.
+func waitFired(t *testing.T, fired chan int) int {
+	select {
+	case v := <-fired:
+		return v
+	case <-time.After(time.Second):
+		t.Fatalf("Expected callback to run")
+	}
+	return 0
+}
+
+// =====
+// TESTS
+// =====
+
+// DEADLINES FIRE IN ORDER WHATEVER THE ORDER THEY WERE SCHEDULED IN.
+func TestSchedulerOrder(t *testing.T) {
+	clock := NewFakeClock(fakeClockRefNow)
+	scheduler := &Scheduler{Clock: clock}
+	defer scheduler.Close()
+
+	fired := make(chan int, 3)
+	for _, minutes := range []int{3, 1, 2} {
+		if err := scheduler.NewTimer().WaitCb(time.Duration(minutes)*time.Minute, func() {
+			fired <- minutes
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for expected := 1; expected <= 3; expected++ {
+		clock.Advance(time.Minute)
+		if got := waitFired(t, fired); got != expected {
+			t.Fatalf("Expected callback %d, got %d", expected, got)
+		}
+	}
+	if scheduler.Pending() != 0 {
+		t.Fatalf("Expected no pending deadlines, got %d", scheduler.Pending())
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	clock := NewFakeClock(fakeClockRefNow)
+	scheduler := &Scheduler{Clock: clock}
+	defer scheduler.Close()
+
+	fired := make(chan int, 2)
+	cancelled := scheduler.NewTimer()
+	kept := scheduler.NewTimer()
+
+	if err := cancelled.WaitCb(time.Minute, func() { fired <- 1 }); err != nil {
+		t.Fatal(err)
+	}
+	if err := cancelled.WaitCb(time.Minute, func() { fired <- 1 }); err != ErrTimerWaited {
+		t.Fatalf("Expected ErrTimerWaited, got %v", err)
+	}
+	if err := kept.WaitCb(2*time.Minute, func() { fired <- 2 }); err != nil {
+		t.Fatal(err)
+	}
+	if err := cancelled.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cancelled.Cancel(); err != ErrTimerNotWaited {
+		t.Fatalf("Expected ErrTimerNotWaited, got %v", err)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if got := waitFired(t, fired); got != 2 {
+		t.Fatalf("Expected only kept callback, got %d", got)
+	}
+
+	// TIMER MAY BE REUSED ONCE FIRED.
+	if err := kept.WaitCb(time.Minute, func() { fired <- 3 }); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+	if got := waitFired(t, fired); got != 3 {
+		t.Fatalf("Expected reused timer callback, got %d", got)
+	}
+}
+
+// MANY DEADLINES ON THE REAL CLOCK.
+func TestSchedulerManyTimers(t *testing.T) {
+	scheduler := &Scheduler{}
+	defer scheduler.Close()
+
+	n := 5000
+	var fired atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := range n {
+		d := time.Duration(i%50) * time.Millisecond
+		if err := scheduler.NewTimer().WaitCb(d, func() {
+			fired.Add(1)
+			wg.Done()
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	if fired.Load() != int64(n) {
+		t.Fatalf("Expected %d callbacks, got %d", n, fired.Load())
+	}
+}
+
+// ==========
+// BENCHMARKS
+// ==========
+
+// Typical controller usage: wait and cancel before the deadline, with many
+// other sessions pending meanwhile.
+func benchmarkWaitCancel(b *testing.B, timerF func() PomoTimerIface) {
+	pending := make([]PomoTimerIface, 10_000)
+	for i := range pending {
+		pending[i] = timerF()
+		if err := pending[i].WaitCb(time.Hour+time.Duration(i)*time.Millisecond, func() {}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	timer := timerF()
+	b.ResetTimer()
+	for range b.N {
+		if err := timer.WaitCb(25*time.Minute, func() {}); err != nil {
+			b.Fatal(err)
+		}
+		if err := timer.Cancel(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	for _, p := range pending {
+		p.Cancel()
+	}
+}
+
+func BenchmarkPomoTimerWaitCancel(b *testing.B) {
+	benchmarkWaitCancel(b, func() PomoTimerIface {
+		return new(PomoTimer)
+	})
+}
+
+func BenchmarkSchedulerWaitCancel(b *testing.B) {
+	scheduler := &Scheduler{}
+	defer scheduler.Close()
+	benchmarkWaitCancel(b, func() PomoTimerIface {
+		return scheduler.NewTimer()
+	})
+}
+
+// Thousands of short deadlines firing at once.
+func benchmarkFire(b *testing.B, timerF func() PomoTimerIface) {
+	n := 1000
+	timers := make([]PomoTimerIface, n)
+	for i := range timers {
+		timers[i] = timerF()
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		var wg sync.WaitGroup
+		wg.Add(n)
+		for _, timer := range timers {
+			if err := timer.WaitCb(time.Millisecond, wg.Done); err != nil {
+				b.Fatal(err)
+			}
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkPomoTimerFire(b *testing.B) {
+	benchmarkFire(b, func() PomoTimerIface {
+		return new(PomoTimer)
+	})
+}
+
+func BenchmarkSchedulerFire(b *testing.B) {
+	scheduler := &Scheduler{}
+	defer scheduler.Close()
+	benchmarkFire(b, func() PomoTimerIface {
+		return scheduler.NewTimer()
+	})
+}
@@ -0,0 +1,60 @@
+package timer
+
+import (
+	"sync"
+	"time"
+)
+
+// PERIODIC CALLBACK WITH LOCK PROTECTION.
+// Ticks follow the clock so they are in step with the timers using it.
+type PomoTicker struct {
+	Clock Clock
+
+	pending    ClockTimer
+	generation uint64
+	locker     sync.Mutex
+}
+
+// Run cb every interval until stopped. Cannot start twice.
+func (t *PomoTicker) Start(interval time.Duration, cb func(now time.Time)) error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	if t.pending != nil {
+		return ErrTimerWaited
+	}
+
+	t.generation++
+	t.schedule(t.generation, interval, cb)
+	return nil
+}
+
+// Call with lock.
+func (t *PomoTicker) schedule(generation uint64, interval time.Duration, cb func(now time.Time)) {
+	clock := ClockOrReal(t.Clock)
+	t.pending = clock.AfterFunc(interval, func() {
+		t.locker.Lock()
+		// If stopped between timer event and lock capture skip
+		if t.pending == nil || t.generation != generation {
+			t.locker.Unlock()
+			return
+		}
+		t.schedule(generation, interval, cb)
+		t.locker.Unlock()
+
+		cb(clock.Now())
+	})
+}
+
+func (t *PomoTicker) Stop() error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	if t.pending == nil {
+		return ErrTimerNotWaited
+	}
+
+	t.pending.Stop()
+	t.pending = nil
+	return nil
+}
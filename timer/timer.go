@@ -1,62 +1,65 @@
 package timer
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
 // TIMER WITH LOCK PROTECTION
+// Callbacks are run by the clock, on their own goroutine for the real clock.
 type PomoTimer struct {
-	cancelChan chan struct{}
+	Clock Clock
+
+	pending ClockTimer
+	// Releases the context of the current wait.
+	stopCtx func() bool
+	// Identifies the current wait so a callback firing at the same time as a
+	// cancel is dismissed.
+	generation uint64
 	locker     sync.Mutex
 }
 
-// THREAD SAFE WAY TO CREATE A NEW CANCEL CHANEL.
-func (t *PomoTimer) setup() error {
+func (t *PomoTimer) WaitCb(d time.Duration, cb func()) error {
+	return t.WaitCtx(context.Background(), d, cb)
+}
+
+// Wait cancelled along with ctx. The callback never runs once ctx is done.
+func (t *PomoTimer) WaitCtx(ctx context.Context, d time.Duration, cb func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	t.locker.Lock()
 	defer t.locker.Unlock()
 
-	if t.cancelChan != nil {
+	// CANNOT WAIT TWICE.
+	if t.pending != nil {
 		return ErrTimerWaited
 	}
 
-	t.cancelChan = make(chan struct{})
-	return nil
-}
-
-func (t *PomoTimer) cbWaitRoutine(d time.Duration, cb func()) {
+	t.generation++
+	generation := t.generation
 
-	teardown := func() bool {
+	t.pending = ClockOrReal(t.Clock).AfterFunc(d, func() {
+		// If cancelled between timer event and lock capture skip
 		t.locker.Lock()
-		defer t.locker.Unlock()
-		if t.cancelChan == nil {
-			return false
-		}
-		close(t.cancelChan)
-		t.cancelChan = nil
-		return true
-	}
-
-	select {
-	case <-time.After(d):
-		// If chan was terminated between timer event and lock capture skip
-		if !teardown() {
+		if t.pending == nil || t.generation != generation {
+			t.locker.Unlock()
 			return
 		}
-		cb()
-		return
-	case <-t.cancelChan:
-		// PROBABLY LOG THIS.
-		return
-	}
-}
+		t.release()
+		t.locker.Unlock()
 
-func (t *PomoTimer) WaitCb(d time.Duration, cb func()) error {
-	// CANNOT WAIT TWICE.
-	if err := t.setup(); err != nil {
-		return err
-	}
-	go t.cbWaitRoutine(d, cb)
+		cb()
+	})
+	t.stopCtx = context.AfterFunc(ctx, func() {
+		t.locker.Lock()
+		defer t.locker.Unlock()
+		if t.pending != nil && t.generation == generation {
+			t.release()
+		}
+	})
 	return nil
 }
 
@@ -64,12 +67,18 @@ func (t *PomoTimer) Cancel() error {
 	t.locker.Lock()
 	defer t.locker.Unlock()
 
-	if t.cancelChan == nil {
+	if t.pending == nil {
 		return ErrTimerNotWaited
 	}
 
-	t.cancelChan <- struct{}{}
-	close(t.cancelChan)
-	t.cancelChan = nil
+	t.release()
 	return nil
 }
+
+// Drop current wait. Call with lock.
+func (t *PomoTimer) release() {
+	t.pending.Stop()
+	t.pending = nil
+	t.stopCtx()
+	t.stopCtx = nil
+}
@@ -0,0 +1,164 @@
+package timer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWallTimerInterval      = time.Second
+	DefaultWallTimerJumpThreshold = 5 * time.Second
+)
+
+// TIMER TARGETING AN ABSOLUTE WALL CLOCK DEADLINE.
+// Go timers follow the monotonic clock, which stands still while the system
+// is suspended. This one checks the wall clock every interval so it is never
+// late by the length of a sleep, and notices when it jumps.
+type WallTimer struct {
+	Clock Clock
+	// How often the deadline is checked. DefaultWallTimerInterval if zero.
+	Interval time.Duration
+	// Delay of a check over the interval taken as a jump.
+	// DefaultWallTimerJumpThreshold if zero.
+	JumpThreshold time.Duration
+
+	pending ClockTimer
+	// Releases the context of the current wait.
+	stopCtx    func() bool
+	generation uint64
+	locker     sync.Mutex
+}
+
+func (t *WallTimer) interval() time.Duration {
+	if t.Interval <= 0 {
+		return DefaultWallTimerInterval
+	}
+	return t.Interval
+}
+
+func (t *WallTimer) jumpThreshold() time.Duration {
+	if t.JumpThreshold <= 0 {
+		return DefaultWallTimerJumpThreshold
+	}
+	return t.JumpThreshold
+}
+
+// Wall clock reading without monotonic part.
+func (t *WallTimer) now() time.Time {
+	return ClockOrReal(t.Clock).Now().Round(0)
+}
+
+// Jumps are ignored. The callback still runs on the wall clock deadline.
+func (t *WallTimer) WaitCb(d time.Duration, cb func()) error {
+	return t.WaitJumpCb(d, cb, nil)
+}
+
+func (t *WallTimer) WaitJumpCb(d time.Duration, cb func(), onJump func(jump TimerJump)) error {
+	return t.WaitJumpCtx(context.Background(), d, cb, onJump)
+}
+
+// Wait cancelled along with ctx. Jumps are ignored.
+func (t *WallTimer) WaitCtx(ctx context.Context, d time.Duration, cb func()) error {
+	return t.WaitJumpCtx(ctx, d, cb, nil)
+}
+
+// Wait cancelled along with ctx. Neither callback runs once ctx is done.
+func (t *WallTimer) WaitJumpCtx(
+	ctx context.Context,
+	d time.Duration,
+	cb func(),
+	onJump func(jump TimerJump),
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	// CANNOT WAIT TWICE.
+	if t.pending != nil {
+		return ErrTimerWaited
+	}
+
+	t.generation++
+	generation := t.generation
+	now := t.now()
+	t.schedule(generation, now, now.Add(d), cb, onJump)
+	t.stopCtx = context.AfterFunc(ctx, func() {
+		t.locker.Lock()
+		defer t.locker.Unlock()
+		if t.pending != nil && t.generation == generation {
+			t.release()
+		}
+	})
+	return nil
+}
+
+// Next check on interval or deadline, whatever comes first. Call with lock.
+func (t *WallTimer) schedule(
+	generation uint64,
+	last, deadline time.Time,
+	cb func(),
+	onJump func(jump TimerJump),
+) {
+	wait := min(t.interval(), deadline.Sub(last))
+	t.pending = ClockOrReal(t.Clock).AfterFunc(wait, func() {
+		t.check(generation, last, last.Add(wait), deadline, cb, onJump)
+	})
+}
+
+func (t *WallTimer) check(
+	generation uint64,
+	last, expected, deadline time.Time,
+	cb func(),
+	onJump func(jump TimerJump),
+) {
+	t.locker.Lock()
+
+	// If cancelled between timer event and lock capture skip
+	if t.pending == nil || t.generation != generation {
+		t.locker.Unlock()
+		return
+	}
+
+	now := t.now()
+
+	if onJump != nil && now.Sub(expected) > t.jumpThreshold() {
+		t.release()
+		t.locker.Unlock()
+		onJump(TimerJump{From: last, To: now})
+		return
+	}
+
+	if !now.Before(deadline) {
+		t.release()
+		t.locker.Unlock()
+		cb()
+		return
+	}
+
+	t.schedule(generation, now, deadline, cb, onJump)
+	t.locker.Unlock()
+}
+
+func (t *WallTimer) Cancel() error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	if t.pending == nil {
+		return ErrTimerNotWaited
+	}
+
+	t.release()
+	return nil
+}
+
+// Drop current wait. Call with lock.
+func (t *WallTimer) release() {
+	t.pending.Stop()
+	t.pending = nil
+	t.stopCtx()
+	t.stopCtx = nil
+}
@@ -0,0 +1,121 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+// ========
+// FIXTURES
+// ========
+
+func wallTimerFactory() (*WallTimer, *FakeClock) {
+	clock := NewFakeClock(fakeClockRefNow)
+	return &WallTimer{Clock: clock}, clock
+}
+
+// =====
+// TESTS
+// =====
+
+func TestWallTimerDeadline(t *testing.T) {
+	timer, clock := wallTimerFactory()
+
+	fired := 0
+	if err := timer.WaitCb(90*time.Second, func() { fired++ }); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(89 * time.Second)
+	if fired != 0 {
+		t.Fatalf("Expected no callback before deadline, got %d", fired)
+	}
+
+	clock.Advance(time.Second)
+	if fired != 1 {
+		t.Fatalf("Expected callback on deadline, got %d", fired)
+	}
+	if clock.Waiters() != 0 {
+		t.Fatalf("Expected no pending checks, got %d", clock.Waiters())
+	}
+}
+
+// SUSPENDED PAST THE DEADLINE. FIRES ON THE FIRST CHECK AFTER WAKE UP INSTEAD
+// OF WAITING FOR THE MONOTONIC TIME LEFT.
+func TestWallTimerSuspendFires(t *testing.T) {
+	timer, clock := wallTimerFactory()
+
+	fired := 0
+	if err := timer.WaitCb(25*time.Minute, func() { fired++ }); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(10 * time.Minute)
+	clock.Jump(time.Hour)
+	clock.Advance(time.Second)
+
+	if fired != 1 {
+		t.Fatalf("Expected callback after wake up, got %d", fired)
+	}
+}
+
+func TestWallTimerJump(t *testing.T) {
+	timer, clock := wallTimerFactory()
+
+	fired := 0
+	jumps := []TimerJump{}
+	err := timer.WaitJumpCb(
+		25*time.Minute,
+		func() { fired++ },
+		func(jump TimerJump) { jumps = append(jumps, jump) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(10 * time.Minute)
+	// SHORT DELAYS ARE NOT JUMPS.
+	clock.Jump(2 * time.Second)
+	clock.Advance(time.Second)
+	if len(jumps) != 0 {
+		t.Fatalf("Expected no jump, got %v", jumps)
+	}
+
+	clock.Jump(time.Minute)
+	clock.Advance(time.Second)
+
+	if fired != 0 {
+		t.Fatalf("Expected no callback on jump, got %d", fired)
+	}
+	if len(jumps) != 1 {
+		t.Fatalf("Expected one jump, got %d", len(jumps))
+	}
+	if d := jumps[0].Duration(); d != time.Minute+time.Second {
+		t.Fatalf("Expected jump of 61s, got %s", d)
+	}
+
+	// WAIT IS OVER AFTER A JUMP.
+	if err := timer.Cancel(); err != ErrTimerNotWaited {
+		t.Fatalf("Expected ErrTimerNotWaited, got %v", err)
+	}
+}
+
+func TestWallTimerCancel(t *testing.T) {
+	timer, clock := wallTimerFactory()
+
+	fired := 0
+	if err := timer.WaitCb(time.Minute, func() { fired++ }); err != nil {
+		t.Fatal(err)
+	}
+	if err := timer.WaitCb(time.Minute, func() { fired++ }); err != ErrTimerWaited {
+		t.Fatalf("Expected ErrTimerWaited, got %v", err)
+	}
+	if err := timer.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Hour)
+	if fired != 0 {
+		t.Fatalf("Expected no callback after cancel, got %d", fired)
+	}
+}